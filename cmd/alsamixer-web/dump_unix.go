@@ -0,0 +1,11 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// dumpSignals trigger a state dump to the log.
+var dumpSignals = []os.Signal{syscall.SIGUSR1}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"errors"
+	"io"
 	"log"
 	"net/http"
 	"os"
@@ -29,15 +30,35 @@ func main() {
 		log.Printf("usage:\n%s", config.HelpText())
 		os.Exit(2)
 	}
+	log.Printf("effective config: %+v", cfg.Redacted())
 
 	hub := sse.NewHub()
 	go hub.Run()
 
-	srv := server.NewServer(cfg, hub)
+	srv, err := server.NewServer(cfg, hub)
+	if err != nil {
+		log.Printf("failed to start server: %v", err)
+		os.Exit(1)
+	}
+	if w := srv.LogWriter(); w != nil {
+		log.SetOutput(io.MultiWriter(log.Writer(), w))
+	}
 
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 
+	// SIGUSR1 dumps goroutines and monitor state without stopping.
+	if len(dumpSignals) > 0 {
+		dumpCh := make(chan os.Signal, 1)
+		signal.Notify(dumpCh, dumpSignals...)
+		go func() {
+			for sig := range dumpCh {
+				log.Printf("received signal %s, dumping state", sig)
+				srv.DumpState(log.Writer())
+			}
+		}()
+	}
+
 	serverErrCh := make(chan error, 1)
 	go func() {
 		serverErrCh <- srv.Start()
@@ -0,0 +1,262 @@
+//go:build linux
+
+package alsa
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"syscall"
+	"unsafe"
+
+	alsalib "github.com/gen2brain/alsa"
+)
+
+// TLV types that describe a control's dB scale, from <sound/tlv.h>.
+const (
+	tlvContainer    = 0
+	tlvDBScale      = 1
+	tlvDBLinear     = 2
+	tlvDBRange      = 3
+	tlvDBMinMax     = 4
+	tlvDBMinMaxMute = 5
+)
+
+// maxTLVBytes bounds the TLV data read for a control; dB scales are a few
+// dozen bytes.
+const maxTLVBytes = 4096
+
+// errNoDBScale is returned for controls whose TLV data has no dB scale this
+// package understands.
+var errNoDBScale = errors.New("control has no dB scale")
+
+// dbSegment maps raw values min-max linearly onto minDB-maxDB, in
+// hundredths of a dB as the kernel reports them.
+type dbSegment struct {
+	min, max     int
+	minDB, maxDB int
+}
+
+// dbScale is a control's dB scale: one segment, or several for controls
+// whose raw range is split into parts with different steps.
+type dbScale []dbSegment
+
+// parseDBScale decodes TLV data for a control with raw range min-max.
+// Linear-volume scales (TLV_DB_LINEAR) are not supported.
+func parseDBScale(tlv []uint32, min, max int) (dbScale, error) {
+	if len(tlv) < 2 {
+		return nil, errNoDBScale
+	}
+	typ, words := tlv[0], int(tlv[1]+3)/4
+	if len(tlv) < 2+words {
+		return nil, fmt.Errorf("truncated TLV data")
+	}
+	data := tlv[2 : 2+words]
+
+	switch typ {
+	case tlvContainer:
+		for len(data) >= 2 {
+			n := 2 + int(data[1]+3)/4
+			if n > len(data) {
+				break
+			}
+			if scale, err := parseDBScale(data[:n], min, max); err == nil {
+				return scale, nil
+			}
+			data = data[n:]
+		}
+		return nil, errNoDBScale
+
+	case tlvDBScale:
+		if len(data) < 2 {
+			return nil, fmt.Errorf("truncated TLV data")
+		}
+		minDB := int(int32(data[0]))
+		step := int(data[1] & 0xffff)
+		return dbScale{{min: min, max: max, minDB: minDB, maxDB: minDB + step*(max-min)}}, nil
+
+	case tlvDBMinMax, tlvDBMinMaxMute:
+		if len(data) < 2 {
+			return nil, fmt.Errorf("truncated TLV data")
+		}
+		return dbScale{{min: min, max: max, minDB: int(int32(data[0])), maxDB: int(int32(data[1]))}}, nil
+
+	case tlvDBRange:
+		var scale dbScale
+		for len(data) >= 4 {
+			n := 4 + int(data[3]+3)/4
+			if n > len(data) {
+				return nil, fmt.Errorf("truncated TLV data")
+			}
+			segMin, segMax := int(int32(data[0])), int(int32(data[1]))
+			inner, err := parseDBScale(data[2:n], segMin, segMax)
+			if err != nil {
+				return nil, err
+			}
+			scale = append(scale, inner...)
+			data = data[n:]
+		}
+		if len(scale) == 0 {
+			return nil, errNoDBScale
+		}
+		return scale, nil
+
+	case tlvDBLinear:
+		return nil, fmt.Errorf("linear dB scales are not supported")
+	}
+	return nil, errNoDBScale
+}
+
+// toDB converts a raw value to dB.
+func (s dbScale) toDB(raw int) float64 {
+	seg := s[len(s)-1]
+	for _, candidate := range s {
+		if raw <= candidate.max {
+			seg = candidate
+			break
+		}
+	}
+	raw = clamp(raw, seg.min, seg.max)
+	if seg.max == seg.min {
+		return float64(seg.minDB) / 100
+	}
+	centi := float64(seg.minDB) + float64(raw-seg.min)*float64(seg.maxDB-seg.minDB)/float64(seg.max-seg.min)
+	return centi / 100
+}
+
+// fromDB converts db to the nearest raw value, clamped to the scale.
+func (s dbScale) fromDB(db float64) int {
+	centi := db * 100
+	if centi <= float64(s[0].minDB) {
+		return s[0].min
+	}
+	for _, seg := range s {
+		if centi > float64(seg.maxDB) {
+			continue
+		}
+		if centi <= float64(seg.minDB) || seg.maxDB == seg.minDB {
+			return seg.min
+		}
+		raw := float64(seg.min) + (centi-float64(seg.minDB))*float64(seg.max-seg.min)/float64(seg.maxDB-seg.minDB)
+		return clamp(int(math.Round(raw)), seg.min, seg.max)
+	}
+	return s[len(s)-1].max
+}
+
+// bounds returns the dB at the bottom and top of the scale.
+func (s dbScale) bounds() (min, max float64) {
+	return s.toDB(s[0].min), s.toDB(s[len(s)-1].max)
+}
+
+// readTLV reads the TLV data of the control with numid on card.
+func readTLV(card uint, numid uint32) ([]uint32, error) {
+	f, err := os.Open(fmt.Sprintf("/dev/snd/controlC%d", card))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	// struct snd_ctl_tlv: numid, length in bytes, then the data
+	buf := make([]uint32, 2+maxTLVBytes/4)
+	buf[0] = numid
+	buf[1] = maxTLVBytes
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), alsalib.SNDRV_CTL_IOCTL_TLV_READ, uintptr(unsafe.Pointer(&buf[0])))
+	if errno != 0 {
+		return nil, fmt.Errorf("reading TLV data: %w", errno)
+	}
+	words := min(int(buf[1]+3)/4, maxTLVBytes/4)
+	return buf[2 : 2+words], nil
+}
+
+// ctlDBScale reads ctl's dB scale from its TLV data.
+func ctlDBScale(card uint, ctl *alsalib.MixerCtl) (dbScale, error) {
+	if ctl.Access()&uint32(alsalib.SNDRV_CTL_ELEM_ACCESS_TLV_READ) == 0 {
+		return nil, errNoDBScale
+	}
+	tlv, err := readTLV(card, ctl.ID())
+	if err != nil {
+		return nil, err
+	}
+	min, _ := ctl.RangeMin()
+	max, _ := ctl.RangeMax()
+	return parseDBScale(tlv, min, max)
+}
+
+// withDBScale looks up control on card and calls fn with it and its dB
+// scale, holding the mixer lock and the card's handle.
+func (m *Mixer) withDBScale(card uint, control string, fn func(*alsalib.MixerCtl, dbScale) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.open {
+		return fmt.Errorf("mixer is closed")
+	}
+
+	mixer, err := m.handles.acquire(card)
+	if err != nil {
+		return fmt.Errorf("failed to open mixer: %w", err)
+	}
+	defer m.handles.release(card)
+
+	ctl, err := ctlByNameFuzzy(mixer, control, volumeSuffixes)
+	if err != nil {
+		return fmt.Errorf("control '%s' not found: %w", control, err)
+	}
+	scale, err := ctlDBScale(card, ctl)
+	if err != nil {
+		return fmt.Errorf("control '%s': %w", control, err)
+	}
+	return fn(ctl, scale)
+}
+
+// GetVolumeDB returns the level of each channel of control in dB.
+func (m *Mixer) GetVolumeDB(card uint, control string) ([]float64, error) {
+	var db []float64
+	err := m.withDBScale(card, control, func(ctl *alsalib.MixerCtl, scale dbScale) error {
+		db = make([]float64, ctl.NumValues())
+		for i := range db {
+			raw, err := ctl.Value(uint(i))
+			if err != nil {
+				return fmt.Errorf("failed to get channel %d value: %w", i, err)
+			}
+			db[i] = scale.toDB(raw)
+		}
+		return nil
+	})
+	return db, err
+}
+
+// SetVolumeDB sets control to the given levels in dB, one per channel, or
+// a single level for every channel. Each level is rounded to the nearest
+// step and clamped to the control's range.
+func (m *Mixer) SetVolumeDB(card uint, control string, values []float64) error {
+	if len(values) == 0 {
+		return fmt.Errorf("no volume values provided")
+	}
+	return m.withDBScale(card, control, func(ctl *alsalib.MixerCtl, scale dbScale) error {
+		for i := 0; i < int(ctl.NumValues()); i++ {
+			db := values[0]
+			if len(values) > 1 {
+				if i >= len(values) {
+					break
+				}
+				db = values[i]
+			}
+			if err := ctl.SetValue(uint(i), scale.fromDB(db)); err != nil {
+				return fmt.Errorf("failed to set channel %d: %w", i, err)
+			}
+		}
+		return nil
+	})
+}
+
+// VolumeDBRange returns the levels in dB at the bottom and top of
+// control's range.
+func (m *Mixer) VolumeDBRange(card uint, control string) (min, max float64, err error) {
+	err = m.withDBScale(card, control, func(ctl *alsalib.MixerCtl, scale dbScale) error {
+		min, max = scale.bounds()
+		return nil
+	})
+	return min, max, err
+}
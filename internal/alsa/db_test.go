@@ -0,0 +1,68 @@
+//go:build linux
+
+package alsa
+
+import "testing"
+
+func TestParseDBScale(t *testing.T) {
+	// HDA "Master Playback Volume": 0-87 in 0.75dB steps from -65.25dB,
+	// muted at the bottom.
+	scale, err := parseDBScale([]uint32{tlvDBScale, 8, uint32(0xffffe683), 0x1004b}, 0, 87)
+	if err != nil {
+		t.Fatalf("parseDBScale() error = %v", err)
+	}
+	if min, max := scale.bounds(); min != -65.25 || max != 0 {
+		t.Errorf("bounds() = %v, %v, want -65.25, 0", min, max)
+	}
+	if db := scale.toDB(60); db != -20.25 {
+		t.Errorf("toDB(60) = %v, want -20.25", db)
+	}
+	if raw := scale.fromDB(-20); raw != 60 {
+		t.Errorf("fromDB(-20) = %d, want 60 (the nearest step)", raw)
+	}
+	if raw := scale.fromDB(6); raw != 87 {
+		t.Errorf("fromDB(6) = %d, want it clamped to 87", raw)
+	}
+	if raw := scale.fromDB(-100); raw != 0 {
+		t.Errorf("fromDB(-100) = %d, want it clamped to 0", raw)
+	}
+}
+
+func TestParseDBScaleRange(t *testing.T) {
+	// 0-10 from -50dB to -20dB, then 11-20 from -18dB to 0dB, wrapped in a
+	// container as some drivers report it.
+	tlv := []uint32{
+		tlvContainer, 56,
+		tlvDBRange, 48,
+		0, 10, tlvDBMinMax, 8, uint32(0xffffec78), uint32(0xfffff830),
+		11, 20, tlvDBMinMax, 8, uint32(0xfffff8f8), 0,
+	}
+	scale, err := parseDBScale(tlv, 0, 20)
+	if err != nil {
+		t.Fatalf("parseDBScale() error = %v", err)
+	}
+	if min, max := scale.bounds(); min != -50 || max != 0 {
+		t.Errorf("bounds() = %v, %v, want -50, 0", min, max)
+	}
+	if db := scale.toDB(5); db != -35 {
+		t.Errorf("toDB(5) = %v, want -35", db)
+	}
+	if raw := scale.fromDB(-9); raw != 16 {
+		t.Errorf("fromDB(-9) = %d, want 16", raw)
+	}
+	if raw := scale.fromDB(-19); raw != 11 {
+		t.Errorf("fromDB(-19), between segments, = %d, want 11", raw)
+	}
+}
+
+func TestParseDBScaleUnsupported(t *testing.T) {
+	if _, err := parseDBScale(nil, 0, 100); err == nil {
+		t.Error("expected an error without TLV data")
+	}
+	if _, err := parseDBScale([]uint32{tlvDBLinear, 8, 0, 0}, 0, 100); err == nil {
+		t.Error("expected an error for a linear dB scale")
+	}
+	if _, err := parseDBScale([]uint32{tlvDBScale, 8, 0}, 0, 100); err == nil {
+		t.Error("expected an error for truncated data")
+	}
+}
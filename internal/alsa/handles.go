@@ -0,0 +1,129 @@
+package alsa
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// handleCache keeps one open mixer handle per card between calls, so that
+// polling does not reopen the control device every time. Handles unused
+// for idleTimeout are closed by a background reaper and reopened on the
+// next call. With an idleTimeout of 0 nothing is cached.
+type handleCache[H io.Closer] struct {
+	open func(card uint) (H, error)
+
+	mu          sync.Mutex
+	idleTimeout time.Duration
+	handles     map[uint]*cachedHandle[H]
+	stop        chan struct{} // stops the reaper; nil when none runs
+}
+
+type cachedHandle[H io.Closer] struct {
+	handle   H
+	inUse    int
+	lastUsed time.Time
+}
+
+func newHandleCache[H io.Closer](open func(card uint) (H, error)) *handleCache[H] {
+	return &handleCache[H]{open: open, handles: make(map[uint]*cachedHandle[H])}
+}
+
+// setIdleTimeout changes how long an unused handle stays open and restarts
+// the reaper accordingly.
+func (c *handleCache[H]) setIdleTimeout(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stop != nil {
+		close(c.stop)
+		c.stop = nil
+	}
+	c.idleTimeout = d
+	if d > 0 {
+		c.stop = make(chan struct{})
+		go c.reapLoop(max(d/2, time.Millisecond), c.stop)
+	}
+}
+
+func (c *handleCache[H]) reapLoop(interval time.Duration, stop chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			c.reap(now)
+		}
+	}
+}
+
+// acquire returns the handle for card, opening it if none is cached. Each
+// successful acquire must be paired with a release.
+func (c *handleCache[H]) acquire(card uint) (H, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if ch, ok := c.handles[card]; ok {
+		ch.inUse++
+		return ch.handle, nil
+	}
+	h, err := c.open(card)
+	if err != nil {
+		return h, err
+	}
+	c.handles[card] = &cachedHandle[H]{handle: h, inUse: 1}
+	return h, nil
+}
+
+// release marks card's handle as idle. Without an idle timeout it is
+// closed straight away.
+func (c *handleCache[H]) release(card uint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch, ok := c.handles[card]
+	if !ok {
+		return
+	}
+	ch.inUse--
+	ch.lastUsed = time.Now()
+	if ch.inUse == 0 && c.idleTimeout <= 0 {
+		ch.handle.Close()
+		delete(c.handles, card)
+	}
+}
+
+// reap closes the handles that have been idle for idleTimeout as of now.
+func (c *handleCache[H]) reap(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for card, ch := range c.handles {
+		if ch.inUse == 0 && now.Sub(ch.lastUsed) >= c.idleTimeout {
+			ch.handle.Close()
+			delete(c.handles, card)
+		}
+	}
+}
+
+// closeIdle closes every handle not currently in use, e.g. because card
+// indexes may now refer to different devices.
+func (c *handleCache[H]) closeIdle() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for card, ch := range c.handles {
+		if ch.inUse == 0 {
+			ch.handle.Close()
+			delete(c.handles, card)
+		}
+	}
+}
+
+// close stops the reaper and closes every idle handle.
+func (c *handleCache[H]) close() {
+	c.setIdleTimeout(0)
+	c.closeIdle()
+}
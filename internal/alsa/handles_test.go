@@ -0,0 +1,105 @@
+package alsa
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeHandle struct {
+	mu     sync.Mutex
+	closed bool
+}
+
+func (h *fakeHandle) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	return nil
+}
+
+func (h *fakeHandle) isClosed() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.closed
+}
+
+func TestHandleCacheReapsIdleHandles(t *testing.T) {
+	var mu sync.Mutex
+	var opened []*fakeHandle
+	cache := newHandleCache(func(card uint) (*fakeHandle, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		h := &fakeHandle{}
+		opened = append(opened, h)
+		return h, nil
+	})
+	cache.setIdleTimeout(50 * time.Millisecond)
+	defer cache.close()
+
+	first, err := cache.acquire(0)
+	if err != nil {
+		t.Fatalf("acquire failed: %v", err)
+	}
+	cache.release(0)
+
+	// Reused while it is still fresh
+	again, _ := cache.acquire(0)
+	cache.release(0)
+	if again != first {
+		t.Fatal("expected the cached handle to be reused")
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !first.isClosed() {
+		if time.Now().After(deadline) {
+			t.Fatal("expected idle handle to be closed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	second, err := cache.acquire(0)
+	if err != nil {
+		t.Fatalf("acquire after reap failed: %v", err)
+	}
+	defer cache.release(0)
+	if second == first || second.isClosed() {
+		t.Error("expected a freshly opened handle after the idle timeout")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(opened) != 2 {
+		t.Errorf("expected 2 opens, got %d", len(opened))
+	}
+}
+
+func TestHandleCacheKeepsHandlesInUse(t *testing.T) {
+	cache := newHandleCache(func(card uint) (*fakeHandle, error) {
+		return &fakeHandle{}, nil
+	})
+	cache.setIdleTimeout(time.Millisecond)
+	defer cache.close()
+
+	h, _ := cache.acquire(1)
+	cache.reap(time.Now().Add(time.Hour))
+	if h.isClosed() {
+		t.Fatal("expected a handle in use not to be reaped")
+	}
+	cache.release(1)
+	cache.reap(time.Now().Add(time.Hour))
+	if !h.isClosed() {
+		t.Error("expected the released handle to be reaped")
+	}
+}
+
+func TestHandleCacheWithoutIdleTimeout(t *testing.T) {
+	cache := newHandleCache(func(card uint) (*fakeHandle, error) {
+		return &fakeHandle{}, nil
+	})
+
+	h, _ := cache.acquire(0)
+	cache.release(0)
+	if !h.isClosed() {
+		t.Error("expected the handle to be closed on release without an idle timeout")
+	}
+}
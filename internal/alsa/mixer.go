@@ -4,11 +4,19 @@
 package alsa
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"log"
+	"os"
 	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	alsalib "github.com/gen2brain/alsa"
 )
@@ -19,10 +27,74 @@ var volumeSuffixes = []string{
 	" Volume",
 }
 
+var switchSuffixes = []string{
+	" Playback Switch",
+	" Capture Switch",
+	" Switch",
+}
+
+// capabilitySuffixes are stripped from volume and switch names alike to
+// find the simple element whose capabilities amixer reports.
+var capabilitySuffixes = append(slices.Clone(volumeSuffixes), switchSuffixes...)
+
+// controlNameCandidates returns the element names to try, in order, when
+// looking up control: the name as given then, for a base name without any
+// volume or switch suffix, the base name with each of suffixes. A name that
+// already has a suffix is only tried as given, so "Mic Playback Switch"
+// never finds "Mic Capture Switch".
+func controlNameCandidates(control string, suffixes []string) []string {
+	for _, suffix := range capabilitySuffixes {
+		if strings.HasSuffix(control, suffix) {
+			return []string{control}
+		}
+	}
+	return append([]string{control}, suffixesOf(control, suffixes)...)
+}
+
+func suffixesOf(base string, suffixes []string) []string {
+	names := make([]string, 0, len(suffixes))
+	for _, suffix := range suffixes {
+		names = append(names, base+suffix)
+	}
+	return names
+}
+
+// ctlByNameFuzzy looks up control by its exact name and, failing that, by
+// the variants from controlNameCandidates, so a base name like "Master"
+// finds "Master Playback Volume". As a last resort the variants are matched
+// ignoring case. The exact-name error is returned if nothing matches.
+func ctlByNameFuzzy(mixer *alsalib.Mixer, control string, suffixes []string) (*alsalib.MixerCtl, error) {
+	candidates := controlNameCandidates(control, suffixes)
+	var firstErr error
+	for _, name := range candidates {
+		ctl, err := mixer.CtlByName(name)
+		if err == nil {
+			return ctl, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	for i := 0; i < mixer.NumCtls(); i++ {
+		ctl, err := mixer.CtlByIndex(uint(i))
+		if err != nil {
+			continue
+		}
+		for _, name := range candidates {
+			if strings.EqualFold(ctl.Name(), name) {
+				return ctl, nil
+			}
+		}
+	}
+	return nil, firstErr
+}
+
 // Card represents an ALSA sound card
 type Card struct {
-	ID   uint   // Card index
-	Name string // Card name
+	ID       uint   // Card index
+	Name     string // Card name
+	LongName string // Driver's long description, e.g. including the USB port
 }
 
 // Control represents an ALSA mixer control
@@ -38,17 +110,51 @@ type Control struct {
 
 // Mixer provides an abstraction layer for ALSA mixer operations
 type Mixer struct {
-	mu   sync.Mutex
-	open bool
+	mu        sync.Mutex
+	open      bool
+	handles   *handleCache[*alsalib.Mixer]
+	lastCards []Card // from the previous ListCards, to notice hotplug
+
+	amixerSuccess  atomic.Uint64
+	amixerFallback atomic.Uint64
+	librarySuccess atomic.Uint64
 }
 
+// SetVolumeStats counts how SetVolume calls were applied: by amixer, or by
+// the ALSA library after amixer failed.
+type SetVolumeStats struct {
+	AmixerSuccess  uint64
+	AmixerFallback uint64 // amixer failed and the library was tried
+	LibrarySuccess uint64 // the library succeeded after amixer failed
+}
+
+// amixerPath is the amixer binary volumes are set and capabilities read
+// with. Tests may point it elsewhere.
+var amixerPath = "amixer"
+
+// amixerMissing makes SetVolume warn only once that amixer is missing.
+var amixerMissing sync.Once
+
 // NewMixer creates a new ALSA mixer instance
 func NewMixer() *Mixer {
 	if _, err := alsalib.EnumerateCards(); err != nil {
 		log.Printf("WARNING: ALSA enumeration failed: %v", err)
 	}
 
-	return &Mixer{open: true}
+	return &Mixer{open: true, handles: newHandleCache(alsalib.MixerOpen)}
+}
+
+// SetHandleIdleTimeout keeps each card's mixer handle open between calls
+// and closes it once unused for d, reopening it on the next call. 0, the
+// default, opens and closes the handle on every call.
+func (m *Mixer) SetHandleIdleTimeout(d time.Duration) {
+	m.handles.setIdleTimeout(d)
+}
+
+// CloseIdleHandles closes every cached mixer handle not in use, so the next
+// call reopens it.
+func (m *Mixer) CloseIdleHandles() {
+	m.handles.closeIdle()
 }
 
 // ListCards enumerates all available sound cards
@@ -69,14 +175,53 @@ func (m *Mixer) ListCards() ([]Card, error) {
 		return nil, fmt.Errorf("no sound cards found")
 	}
 
+	longNames := map[int]string{}
+	if content, err := os.ReadFile("/proc/asound/cards"); err == nil {
+		longNames = parseCardLongNames(string(content))
+	}
+
 	cards := make([]Card, 0, len(soundCards))
 	for _, c := range soundCards {
-		cards = append(cards, Card{ID: uint(c.ID), Name: c.Name})
+		longName := longNames[c.ID]
+		if longName == "" {
+			longName = c.Description
+		}
+		cards = append(cards, Card{ID: uint(c.ID), Name: c.Name, LongName: longName})
 	}
 
+	// After a hotplug an index may refer to another device, so cached
+	// handles must not be reused
+	if m.lastCards != nil && !slices.Equal(cards, m.lastCards) {
+		m.handles.closeIdle()
+	}
+	m.lastCards = cards
+
 	return cards, nil
 }
 
+var cardHeaderRe = regexp.MustCompile(`^\s*(\d+)\s+\[`)
+
+// parseCardLongNames extracts each card's long name from the contents of
+// /proc/asound/cards, where it is the indented line after the card's header.
+// Identical USB devices differ only here, by the port they are plugged into.
+func parseCardLongNames(content string) map[int]string {
+	names := make(map[int]string)
+	current := -1
+	for _, line := range strings.Split(content, "\n") {
+		if m := cardHeaderRe.FindStringSubmatch(line); m != nil {
+			current, _ = strconv.Atoi(m[1])
+			continue
+		}
+		if current >= 0 {
+			if name := strings.TrimSpace(line); name != "" {
+				names[current] = name
+			}
+			current = -1
+		}
+	}
+	return names
+}
+
 // ListControls enumerates all mixer controls for a given card.
 // It uses the underlying library which handles proper sorting (matching alsamixer).
 func (m *Mixer) ListControls(card uint) ([]Control, error) {
@@ -87,11 +232,11 @@ func (m *Mixer) ListControls(card uint) ([]Control, error) {
 		return nil, fmt.Errorf("mixer is closed")
 	}
 
-	mixer, err := alsalib.MixerOpen(card)
+	mixer, err := m.handles.acquire(card)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open mixer for card %d: %w", card, err)
 	}
-	defer mixer.Close()
+	defer m.handles.release(card)
 
 	var controls []Control
 	for i := 0; i < mixer.NumCtls(); i++ {
@@ -138,13 +283,13 @@ func (m *Mixer) GetVolume(card uint, control string) ([]int, error) {
 		return nil, fmt.Errorf("mixer is closed")
 	}
 
-	mixer, err := alsalib.MixerOpen(card)
+	mixer, err := m.handles.acquire(card)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open mixer: %w", err)
 	}
-	defer mixer.Close()
+	defer m.handles.release(card)
 
-	ctl, err := mixer.CtlByName(control)
+	ctl, err := ctlByNameFuzzy(mixer, control, volumeSuffixes)
 	if err != nil {
 		return nil, fmt.Errorf("control '%s' not found: %w", control, err)
 	}
@@ -201,6 +346,7 @@ func (m *Mixer) SetVolume(card uint, control string, values []int) error {
 	if len(values) == 0 {
 		return fmt.Errorf("no volume values provided")
 	}
+	values = clampPercents(values)
 
 	// Convert control name from UI format (e.g., "Speaker Playback Volume") to
 	// ALSA format (e.g., "Speaker")
@@ -219,7 +365,7 @@ func (m *Mixer) SetVolume(card uint, control string, values []int) error {
 	//   - value with % suffix: treated as percentage
 	//   - 100 without %: treated as 100% (special case)
 	// Since UI works in percentages, always add % suffix for consistency
-	cmd := exec.Command("amixer", "-c", fmt.Sprintf("%d", card), "sset", alsaControl)
+	cmd := exec.Command(amixerPath, "-c", fmt.Sprintf("%d", card), "sset", alsaControl)
 	if len(values) == 1 {
 		// Single value: set both/all channels to the same percentage
 		cmd.Args = append(cmd.Args, fmt.Sprintf("%d%%", values[0]))
@@ -234,23 +380,44 @@ func (m *Mixer) SetVolume(card uint, control string, values []int) error {
 
 	output, err := cmd.CombinedOutput()
 	if err != nil {
-		log.Printf("SetVolume: amixer failed for '%s': %v output: %s", alsaControl, err, string(output))
+		if errors.Is(err, exec.ErrNotFound) || errors.Is(err, fs.ErrNotExist) {
+			amixerMissing.Do(func() {
+				log.Printf("WARNING: %s not found; setting volumes through the ALSA library instead", amixerPath)
+			})
+		} else {
+			log.Printf("SetVolume: amixer failed for '%s': %v output: %s", alsaControl, err, string(output))
+		}
 		// Fall back to library method
-		return m.setVolumeLibrary(card, control, values)
+		m.amixerFallback.Add(1)
+		if err := m.setVolumeLibrary(card, control, values); err != nil {
+			return err
+		}
+		m.librarySuccess.Add(1)
+		return nil
 	}
 
+	m.amixerSuccess.Add(1)
 	return nil
 }
 
+// SetVolumeStats returns how SetVolume calls have been applied so far.
+func (m *Mixer) SetVolumeStats() SetVolumeStats {
+	return SetVolumeStats{
+		AmixerSuccess:  m.amixerSuccess.Load(),
+		AmixerFallback: m.amixerFallback.Load(),
+		LibrarySuccess: m.librarySuccess.Load(),
+	}
+}
+
 // setVolumeLibrary is the fallback volume setter using the alsa library
 func (m *Mixer) setVolumeLibrary(card uint, control string, values []int) error {
-	mixer, err := alsalib.MixerOpen(card)
+	mixer, err := m.handles.acquire(card)
 	if err != nil {
 		return fmt.Errorf("failed to open mixer: %w", err)
 	}
-	defer mixer.Close()
+	defer m.handles.release(card)
 
-	ctl, err := mixer.CtlByName(control)
+	ctl, err := ctlByNameFuzzy(mixer, control, volumeSuffixes)
 	if err != nil {
 		return err
 	}
@@ -265,12 +432,7 @@ func (m *Mixer) setVolumeLibrary(card uint, control string, values []int) error
 
 	// Set each channel individually
 	if len(values) == 1 {
-		var raw int
-		if max > min {
-			raw = min + (values[0]*(max-min))/100
-		} else {
-			raw = min
-		}
+		raw := percentToRaw(values[0], min, max)
 		for i := 0; i < numChannels; i++ {
 			if err := ctl.SetValue(uint(i), raw); err != nil {
 				return fmt.Errorf("failed to set channel %d: %w", i, err)
@@ -278,12 +440,7 @@ func (m *Mixer) setVolumeLibrary(card uint, control string, values []int) error
 		}
 	} else {
 		for i := 0; i < numChannels && i < len(values); i++ {
-			var raw int
-			if max > min {
-				raw = min + (values[i]*(max-min))/100
-			} else {
-				raw = min
-			}
+			raw := percentToRaw(values[i], min, max)
 			if err := ctl.SetValue(uint(i), raw); err != nil {
 				return fmt.Errorf("failed to set channel %d: %w", i, err)
 			}
@@ -293,6 +450,82 @@ func (m *Mixer) setVolumeLibrary(card uint, control string, values []int) error
 	return nil
 }
 
+// GetRawVolume returns the raw hardware value of each channel of control,
+// without converting to a percentage.
+func (m *Mixer) GetRawVolume(card uint, control string) ([]int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.open {
+		return nil, fmt.Errorf("mixer is closed")
+	}
+
+	mixer, err := m.handles.acquire(card)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open mixer: %w", err)
+	}
+	defer m.handles.release(card)
+
+	ctl, err := ctlByNameFuzzy(mixer, control, volumeSuffixes)
+	if err != nil {
+		return nil, fmt.Errorf("control '%s' not found: %w", control, err)
+	}
+
+	values := make([]int, ctl.NumValues())
+	for i := range values {
+		val, err := ctl.Value(uint(i))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get channel %d value: %w", i, err)
+		}
+		values[i] = val
+	}
+	return values, nil
+}
+
+// SetRawVolume writes raw hardware values to control, one per channel, or
+// a single value to every channel. Values are clamped to the control's
+// range. Unlike SetVolume it always goes through the library, since amixer
+// would treat small numbers as percentages.
+func (m *Mixer) SetRawVolume(card uint, control string, values []int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.open {
+		return fmt.Errorf("mixer is closed")
+	}
+	if len(values) == 0 {
+		return fmt.Errorf("no volume values provided")
+	}
+
+	mixer, err := m.handles.acquire(card)
+	if err != nil {
+		return fmt.Errorf("failed to open mixer: %w", err)
+	}
+	defer m.handles.release(card)
+
+	ctl, err := ctlByNameFuzzy(mixer, control, volumeSuffixes)
+	if err != nil {
+		return err
+	}
+
+	min, _ := ctl.RangeMin()
+	max, _ := ctl.RangeMax()
+	for i := 0; i < int(ctl.NumValues()); i++ {
+		raw := values[0]
+		if len(values) > 1 {
+			if i >= len(values) {
+				break
+			}
+			raw = values[i]
+		}
+		raw = clamp(raw, min, max)
+		if err := ctl.SetValue(uint(i), raw); err != nil {
+			return fmt.Errorf("failed to set channel %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
 // GetMute retrieves the mute state for a control.
 // Returns true if ALL channels are muted, false otherwise.
 func (m *Mixer) GetMute(card uint, control string) (bool, error) {
@@ -303,13 +536,13 @@ func (m *Mixer) GetMute(card uint, control string) (bool, error) {
 		return false, fmt.Errorf("mixer is closed")
 	}
 
-	mixer, err := alsalib.MixerOpen(card)
+	mixer, err := m.handles.acquire(card)
 	if err != nil {
 		return false, err
 	}
-	defer mixer.Close()
+	defer m.handles.release(card)
 
-	ctl, err := mixer.CtlByName(control)
+	ctl, err := ctlByNameFuzzy(mixer, control, switchSuffixes)
 	if err != nil {
 		return false, fmt.Errorf("control not found: %s", control)
 	}
@@ -344,13 +577,13 @@ func (m *Mixer) SetMute(card uint, control string, muted bool) error {
 		return fmt.Errorf("mixer is closed")
 	}
 
-	mixer, err := alsalib.MixerOpen(card)
+	mixer, err := m.handles.acquire(card)
 	if err != nil {
 		return err
 	}
-	defer mixer.Close()
+	defer m.handles.release(card)
 
-	ctl, err := mixer.CtlByName(control)
+	ctl, err := ctlByNameFuzzy(mixer, control, switchSuffixes)
 	if err != nil {
 		return fmt.Errorf("control not found: %s", control)
 	}
@@ -423,16 +656,16 @@ func (m *Mixer) HasCaptureSwitch(card uint, control string) (bool, error) {
 // getControlCapabilities runs amixer to get the capabilities string for a control.
 // The capabilities string contains indicators like pvolume, pswitch, cvolume, cswitch.
 func (m *Mixer) getControlCapabilities(card uint, control string) (string, error) {
-	// Extract base name (remove " Playback Volume", " Capture Volume", " Volume" suffixes)
+	// Extract base name (remove " Playback Volume", " Capture Switch", etc.)
 	baseName := control
-	for _, suffix := range volumeSuffixes {
+	for _, suffix := range capabilitySuffixes {
 		if strings.HasSuffix(baseName, suffix) {
 			baseName = strings.TrimSuffix(baseName, suffix)
 			break
 		}
 	}
 
-	cmd := exec.Command("amixer", "-c", fmt.Sprintf("%d", card), "sget", baseName)
+	cmd := exec.Command(amixerPath, "-c", fmt.Sprintf("%d", card), "sget", baseName)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return "", fmt.Errorf("failed to get capabilities for '%s': %w", baseName, err)
@@ -463,6 +696,7 @@ func (m *Mixer) Close() error {
 	}
 
 	m.open = false
+	m.handles.close()
 	return nil
 }
 
@@ -472,3 +706,40 @@ func (m *Mixer) IsOpen() bool {
 	defer m.mu.Unlock()
 	return m.open
 }
+
+// UnavailableReason explains why the mixer cannot be used, or returns an
+// empty string if it is open.
+func (m *Mixer) UnavailableReason() string {
+	if m.IsOpen() {
+		return ""
+	}
+	return "ALSA mixer is closed"
+}
+
+// clampPercents returns a copy of values with each limited to 0-100, so
+// neither amixer nor the driver sees an out-of-range volume.
+func clampPercents(values []int) []int {
+	clamped := make([]int, len(values))
+	for i, v := range values {
+		clamped[i] = clamp(v, 0, 100)
+	}
+	return clamped
+}
+
+// percentToRaw converts a volume percentage to a raw value within min-max.
+func percentToRaw(percent, min, max int) int {
+	if max <= min {
+		return min
+	}
+	return clamp(min+(clamp(percent, 0, 100)*(max-min))/100, min, max)
+}
+
+func clamp(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
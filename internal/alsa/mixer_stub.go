@@ -2,12 +2,16 @@
 
 package alsa
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 // Card represents an ALSA sound card (stub implementation for non-Linux platforms).
 type Card struct {
-	ID   uint
-	Name string
+	ID       uint
+	Name     string
+	LongName string
 }
 
 // Control represents an ALSA mixer control (stub implementation for non-Linux platforms).
@@ -24,9 +28,26 @@ type Control struct {
 // Mixer is a no-op stub used on platforms where ALSA is not available.
 type Mixer struct{}
 
+// SetVolumeStats counts how SetVolume calls were applied (stub
+// implementation for non-Linux platforms).
+type SetVolumeStats struct {
+	AmixerSuccess  uint64
+	AmixerFallback uint64
+	LibrarySuccess uint64
+}
+
 // NewMixer creates a stub mixer.
 func NewMixer() *Mixer { return &Mixer{} }
 
+// SetHandleIdleTimeout is a no-op on the stub mixer.
+func (m *Mixer) SetHandleIdleTimeout(d time.Duration) {}
+
+// CloseIdleHandles is a no-op on the stub mixer.
+func (m *Mixer) CloseIdleHandles() {}
+
+// SetVolumeStats returns zero counts on the stub mixer.
+func (m *Mixer) SetVolumeStats() SetVolumeStats { return SetVolumeStats{} }
+
 // ListCards returns an error indicating ALSA is unavailable.
 func (m *Mixer) ListCards() ([]Card, error) {
 	return nil, fmt.Errorf("alsa mixer is not supported on this platform")
@@ -47,6 +68,16 @@ func (m *Mixer) SetVolume(card uint, control string, values []int) error {
 	return fmt.Errorf("alsa mixer is not supported on this platform")
 }
 
+// GetRawVolume returns an error indicating ALSA is unavailable.
+func (m *Mixer) GetRawVolume(card uint, control string) ([]int, error) {
+	return nil, fmt.Errorf("alsa mixer is not supported on this platform")
+}
+
+// SetRawVolume returns an error indicating ALSA is unavailable.
+func (m *Mixer) SetRawVolume(card uint, control string, values []int) error {
+	return fmt.Errorf("alsa mixer is not supported on this platform")
+}
+
 // GetMute returns an error indicating ALSA is unavailable.
 func (m *Mixer) GetMute(card uint, control string) (bool, error) {
 	return false, fmt.Errorf("alsa mixer is not supported on this platform")
@@ -63,6 +94,11 @@ func (m *Mixer) Close() error { return nil }
 // IsOpen always reports false for the stub mixer.
 func (m *Mixer) IsOpen() bool { return false }
 
+// UnavailableReason reports that ALSA is not available on this platform.
+func (m *Mixer) UnavailableReason() string {
+	return "ALSA is not supported on this platform"
+}
+
 // HasPlaybackVolume returns false with an error for stub.
 func (m *Mixer) HasPlaybackVolume(card uint, control string) (bool, error) {
 	return false, fmt.Errorf("alsa mixer is not supported on this platform")
@@ -82,3 +118,18 @@ func (m *Mixer) HasCaptureVolume(card uint, control string) (bool, error) {
 func (m *Mixer) HasCaptureSwitch(card uint, control string) (bool, error) {
 	return false, fmt.Errorf("alsa mixer is not supported on this platform")
 }
+
+// GetVolumeDB returns an error indicating ALSA is unavailable.
+func (m *Mixer) GetVolumeDB(card uint, control string) ([]float64, error) {
+	return nil, fmt.Errorf("alsa mixer is not supported on this platform")
+}
+
+// SetVolumeDB returns an error indicating ALSA is unavailable.
+func (m *Mixer) SetVolumeDB(card uint, control string, values []float64) error {
+	return fmt.Errorf("alsa mixer is not supported on this platform")
+}
+
+// VolumeDBRange returns an error indicating ALSA is unavailable.
+func (m *Mixer) VolumeDBRange(card uint, control string) (min, max float64, err error) {
+	return 0, 0, fmt.Errorf("alsa mixer is not supported on this platform")
+}
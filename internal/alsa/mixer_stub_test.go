@@ -12,6 +12,9 @@ func TestStubMixerUnsupported(t *testing.T) {
 	if m.IsOpen() {
 		t.Fatal("stub mixer should not report open")
 	}
+	if reason := m.UnavailableReason(); reason != "ALSA is not supported on this platform" {
+		t.Fatalf("unexpected UnavailableReason() on non-linux: %q", reason)
+	}
 
 	if _, err := m.ListCards(); err == nil {
 		t.Fatal("expected ListCards() to error on non-linux")
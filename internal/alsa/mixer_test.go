@@ -3,6 +3,7 @@
 package alsa
 
 import (
+	"path/filepath"
 	"testing"
 )
 
@@ -29,6 +30,9 @@ func TestMixerClose(t *testing.T) {
 	if mixer.IsOpen() {
 		t.Error("Mixer should be closed after Close()")
 	}
+	if reason := mixer.UnavailableReason(); reason == "" {
+		t.Error("Closed mixer should report an unavailable reason")
+	}
 
 	// Closing again should return an error
 	err = mixer.Close()
@@ -421,6 +425,55 @@ func TestSetVolumeValidation(t *testing.T) {
 	}
 }
 
+// TestSetVolumeClampsValues tests that out-of-range percentages are clamped
+// per channel before they reach amixer or the driver
+func TestSetVolumeClampsValues(t *testing.T) {
+	got := clampPercents([]int{150, -10})
+	if len(got) != 2 || got[0] != 100 || got[1] != 0 {
+		t.Errorf("clampPercents([150 -10]) = %v, want [100 0]", got)
+	}
+
+	for _, tt := range []struct{ percent, min, max, want int }{
+		{150, 0, 87, 87},
+		{-10, 0, 87, 0},
+		{150, -10239, 400, 400},
+		{-10, -10239, 400, -10239},
+		{50, 0, 100, 50},
+	} {
+		if got := percentToRaw(tt.percent, tt.min, tt.max); got != tt.want {
+			t.Errorf("percentToRaw(%d, %d, %d) = %d, want %d", tt.percent, tt.min, tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestSetVolumeCountsFallback(t *testing.T) {
+	origAmixer := amixerPath
+	amixerPath = filepath.Join(t.TempDir(), "amixer")
+	defer func() { amixerPath = origAmixer }()
+
+	m := NewMixer()
+	defer m.Close()
+
+	// amixer is missing, so the library is tried; whether it works depends
+	// on the machine having card 0.
+	err := m.SetVolume(0, "Master Playback Volume", []int{50})
+
+	stats := m.SetVolumeStats()
+	if stats.AmixerSuccess != 0 {
+		t.Errorf("expected no amixer successes, got %d", stats.AmixerSuccess)
+	}
+	if stats.AmixerFallback != 1 {
+		t.Errorf("expected 1 fallback to the library, got %d", stats.AmixerFallback)
+	}
+	wantLibrary := uint64(1)
+	if err != nil {
+		wantLibrary = 0
+	}
+	if stats.LibrarySuccess != wantLibrary {
+		t.Errorf("expected %d library successes (SetVolume error: %v), got %d", wantLibrary, err, stats.LibrarySuccess)
+	}
+}
+
 // TestGetVolumeZeroRange tests that GetVolume handles zero-range controls gracefully
 // This tests the safeguard we added for max == min edge case
 func TestGetVolumeZeroRange(t *testing.T) {
@@ -434,3 +487,72 @@ func TestGetVolumeZeroRange(t *testing.T) {
 	t.Log("Zero-range control handling is protected by max == min check returning error")
 	t.Log("And division safeguards: if max > min { divide } else { fallback }")
 }
+
+// TestParseCardLongNames tests extracting long names from /proc/asound/cards
+func TestParseCardLongNames(t *testing.T) {
+	content := ` 0 [PCH            ]: HDA-Intel - HDA Intel PCH
+                      HDA Intel PCH at 0xf7f10000 irq 32
+ 1 [Device         ]: USB-Audio - USB Audio Device
+                      C-Media USB Audio Device at usb-0000:00:14.0-1, full speed
+ 2 [Device_1       ]: USB-Audio - USB Audio Device
+                      C-Media USB Audio Device at usb-0000:00:14.0-2, full speed
+`
+
+	names := parseCardLongNames(content)
+
+	want := map[int]string{
+		0: "HDA Intel PCH at 0xf7f10000 irq 32",
+		1: "C-Media USB Audio Device at usb-0000:00:14.0-1, full speed",
+		2: "C-Media USB Audio Device at usb-0000:00:14.0-2, full speed",
+	}
+	for id, name := range want {
+		if names[id] != name {
+			t.Errorf("card %d long name = %q, want %q", id, names[id], name)
+		}
+	}
+}
+
+// TestControlNameCandidates tests that base and variant names resolve to the
+// element ALSA actually has
+func TestControlNameCandidates(t *testing.T) {
+	elements := map[string]bool{
+		"Master Playback Volume": true,
+		"Master Playback Switch": true,
+		"Capture Volume":         true,
+		"Mic Boost":              true,
+		"Mic Capture Volume":     true,
+		"Mic Capture Switch":     true,
+	}
+	resolve := func(control string, suffixes []string) string {
+		for _, name := range controlNameCandidates(control, suffixes) {
+			if elements[name] {
+				return name
+			}
+		}
+		return ""
+	}
+
+	tests := []struct {
+		control  string
+		suffixes []string
+		want     string
+	}{
+		{"Master", volumeSuffixes, "Master Playback Volume"},
+		{"Master Playback Volume", volumeSuffixes, "Master Playback Volume"},
+		{"Master", switchSuffixes, "Master Playback Switch"},
+		{"Capture", volumeSuffixes, "Capture Volume"},
+		{"Mic Boost", volumeSuffixes, "Mic Boost"},
+		{"Mic", switchSuffixes, "Mic Capture Switch"},
+		{"Speaker", volumeSuffixes, ""},
+		// Fully qualified names are not turned into the other direction.
+		{"Mic Playback Switch", switchSuffixes, ""},
+		{"Mic Playback Volume", volumeSuffixes, ""},
+		{"Master Volume", volumeSuffixes, ""},
+	}
+
+	for _, tt := range tests {
+		if got := resolve(tt.control, tt.suffixes); got != tt.want {
+			t.Errorf("resolve(%q) = %q, want %q (candidates %v)", tt.control, got, tt.want, controlNameCandidates(tt.control, tt.suffixes))
+		}
+	}
+}
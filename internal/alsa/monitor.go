@@ -1,8 +1,13 @@
 package alsa
 
 import (
+	"fmt"
+	"io"
 	"log"
+	"maps"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -23,19 +28,73 @@ type Hub interface {
 }
 
 type Monitor struct {
-	mixer       *Mixer
+	mixer       Reader
 	hub         Hub
 	ticker      *time.Ticker
 	stopCh      chan struct{}
 	wg          sync.WaitGroup
 	lastState   *StateSnapshot
 	mu          sync.Mutex
+	pollMu      sync.Mutex // held for each tick, so Suspend can wait it out
 	watcher     *fsnotify.Watcher
 	configPaths []string
+	cardsDir    string // watched as a hint that cards changed; empty if not watchable
+
+	onTopologyChange func()
+	onChange         func(delta *StateSnapshot)
+	cardFilter       func(Card) bool
+	volumeThreshold  int
+	pollInterval     time.Duration
+	idlePollInterval time.Duration
+	lastActivity     time.Time
+	activity         chan struct{}
+	localChanges     map[string]time.Time
+	changedAt        map[string]time.Time
+	suspended        int           // Suspend calls not yet resumed
+	resumed          chan struct{} // signalled when the last suspension ends
+	cardsHint        chan struct{} // signalled when the watched card list changes
+	running          bool
+	failures         int          // consecutive polls that could not read the mixer
+	lastPoll         time.Time    // when the mixer was last read successfully
+	cardFailures     map[uint]int // consecutive failures to list each card's controls
+	cardIDs          []uint       // card IDs ListCards last returned, before filtering
+}
+
+// localChangeWindow is how long monitor updates for a control are held back
+// after the HTTP API changed it. The handler has already broadcast the value
+// the user asked for; reporting the hardware's rounded reading straight away
+// makes sliders flap.
+const localChangeWindow = 500 * time.Millisecond
+
+const (
+	// defaultPollInterval is how often the monitor reads the mixer unless
+	// SetPollInterval says otherwise.
+	defaultPollInterval = 100 * time.Millisecond
+	// idleAfter is how long after the last client activity the monitor
+	// drops to its idle poll interval.
+	idleAfter = 30 * time.Second
+)
+
+// cardFailureLimit is how many polls in a row may fail to list a card's
+// controls before the monitor stops polling that card. It is polled again
+// once the card list changes or Rescan is called.
+const cardFailureLimit = 5
+
+// Reader is the part of a mixer the monitor polls. *Mixer implements it.
+type Reader interface {
+	ListCards() ([]Card, error)
+	ListControls(card uint) ([]Control, error)
+	GetVolume(card uint, control string) ([]int, error)
+	GetMute(card uint, control string) (bool, error)
 }
 
 type StateSnapshot struct {
 	Cards map[uint]CardState
+
+	// listed holds the IDs of the cards ListCards returned, after the card
+	// filter, including cards whose controls could not be read. Only
+	// snapshots from getCurrentState set it.
+	listed []uint
 }
 
 type CardState struct {
@@ -47,7 +106,7 @@ type ControlState struct {
 	Mute   bool
 }
 
-func NewMonitor(mixer *Mixer, hub Hub, monitorFile string) *Monitor {
+func NewMonitor(mixer Reader, hub Hub, monitorFile string) *Monitor {
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
 		log.Fatalf("failed to create file watcher: %v", err)
@@ -62,26 +121,151 @@ func NewMonitor(mixer *Mixer, hub Hub, monitorFile string) *Monitor {
 		mixer:       mixer,
 		hub:         hub,
 		stopCh:      make(chan struct{}),
+		activity:    make(chan struct{}, 1),
+		resumed:     make(chan struct{}, 1),
+		cardsHint:   make(chan struct{}, 1),
 		watcher:     watcher,
 		configPaths: paths,
 	}
 
-	for _, path := range monitor.configPaths {
-		if _, err := os.Stat(path); err == nil {
-			if err := monitor.watcher.Add(path); err != nil {
-				log.Printf("failed to watch %s: %v", path, err)
+	for _, status := range checkWatchPaths(monitor.configPaths) {
+		if status.Reason == "" {
+			if err := monitor.watcher.Add(status.Path); err != nil {
+				status.Reason = err.Error()
 			}
-		} else if os.IsNotExist(err) {
-			log.Printf("config file not found: %s, skipping watch", path)
+		}
+		if status.Reason != "" {
+			log.Printf("%s", status.warning())
 		} else {
-			log.Printf("error stating config file %s: %v", path, err)
+			log.Printf("Watching config file %s for changes", status.Path)
 		}
 	}
 
+	if err := monitor.WatchCards(sndDevPath); err != nil {
+		log.Printf("not watching %s for card hotplug: %v", sndDevPath, err)
+	}
+
 	return monitor
 }
 
+// sndDevPath holds the ALSA device nodes; udev adds and removes a card's
+// nodes when it is plugged in or removed.
+const sndDevPath = "/dev/snd"
+
+// WatchCards watches dir, a directory of sound device nodes like /dev/snd,
+// and polls straight away whenever an entry appears or disappears. It is
+// only a hint: the poll itself detects cards being plugged in or removed,
+// just up to a poll interval later.
+func (m *Monitor) WatchCards(dir string) error {
+	if err := m.watcher.Add(dir); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	m.cardsDir = dir
+	m.mu.Unlock()
+	return nil
+}
+
+// watchStatus records whether a monitored config file can be watched and,
+// if not, why.
+type watchStatus struct {
+	Path   string
+	Reason string // empty if the path is watchable
+}
+
+// warning returns a log line explaining that the path is not being watched.
+func (s watchStatus) warning() string {
+	return fmt.Sprintf("WARNING: not watching %s (%s); config-change events will not fire for it", s.Path, s.Reason)
+}
+
+// checkWatchPaths stats each path and reports which of them can be watched.
+func checkWatchPaths(paths []string) []watchStatus {
+	statuses := make([]watchStatus, 0, len(paths))
+	for _, path := range paths {
+		status := watchStatus{Path: path}
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			status.Reason = "file does not exist"
+		} else if err != nil {
+			status.Reason = err.Error()
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// OnTopologyChange registers a callback invoked when the set of cards or the
+// set of controls on a card changes between two monitor ticks.
+func (m *Monitor) OnTopologyChange(callback func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onTopologyChange = callback
+}
+
+// OnChange registers a callback invoked with every delta the monitor
+// broadcasts. The callback must not modify the delta.
+func (m *Monitor) OnChange(callback func(delta *StateSnapshot)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onChange = callback
+}
+
+// SetCardFilter restricts monitoring to the cards for which filter returns
+// true. Changes on other cards are neither tracked nor broadcast.
+func (m *Monitor) SetCardFilter(filter func(Card) bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cardFilter = filter
+}
+
+// SetVolumeThreshold makes the monitor ignore volume changes smaller than
+// threshold percent, so noisy auto-gain controls don't flood clients. Mute
+// changes are always reported. The default of 0 reports every change.
+func (m *Monitor) SetVolumeThreshold(threshold int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.volumeThreshold = threshold
+}
+
+// SetPollInterval sets how often the mixer is read: every interval while
+// clients are active, slowing to idle once there has been no activity for
+// idleAfter. An idle interval no longer than interval disables slowing down.
+func (m *Monitor) SetPollInterval(interval, idle time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pollInterval = interval
+	m.idlePollInterval = idle
+}
+
+// NoteActivity records client activity, such as a new connection, so the
+// monitor polls at its fast interval for the next idleAfter.
+func (m *Monitor) NoteActivity() {
+	m.mu.Lock()
+	m.lastActivity = time.Now()
+	m.mu.Unlock()
+
+	select {
+	case m.activity <- struct{}{}:
+	default:
+	}
+}
+
+// currentPollInterval returns the poll interval to use at now. The caller
+// must hold m.mu.
+func (m *Monitor) currentPollInterval(now time.Time) time.Duration {
+	interval := m.pollInterval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	if m.idlePollInterval > interval && now.Sub(m.lastActivity) >= idleAfter {
+		return m.idlePollInterval
+	}
+	return interval
+}
+
 func (m *Monitor) Start() {
+	m.mu.Lock()
+	m.running = true
+	m.mu.Unlock()
 	m.wg.Add(1)
 	go m.monitorLoop()
 	m.wg.Add(1)
@@ -93,37 +277,112 @@ func (m *Monitor) Stop() {
 	close(m.stopCh)
 	m.watcher.Close()
 	m.wg.Wait()
+	m.mu.Lock()
+	m.running = false
+	m.mu.Unlock()
 	log.Println("ALSA monitor stopped")
 }
 
+// Running reports whether the monitor has been started and not stopped.
+func (m *Monitor) Running() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.running
+}
+
+// ConsecutiveFailures returns how many polls in a row failed to read the
+// mixer; 0 once a poll succeeds again.
+func (m *Monitor) ConsecutiveFailures() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.failures
+}
+
+// WriteState writes a human-readable account of the monitor's status and
+// its latest reading of every control to w, for diagnosing a monitor that
+// has stopped reporting changes. If the monitor's lock is held, as in a
+// deadlock, it says so instead of waiting.
+func (m *Monitor) WriteState(w io.Writer) {
+	if !m.mu.TryLock() {
+		fmt.Fprintln(w, "monitor state locked")
+		return
+	}
+	defer m.mu.Unlock()
+
+	lastPoll := "never"
+	if !m.lastPoll.IsZero() {
+		lastPoll = m.lastPoll.Format(time.RFC3339Nano)
+	}
+	fmt.Fprintf(w, "monitor: running=%t suspended=%d failures=%d interval=%v last poll=%s\n",
+		m.running, m.suspended, m.failures, m.currentPollInterval(time.Now()), lastPoll)
+
+	for _, card := range slices.Sorted(maps.Keys(m.cardFailures)) {
+		if failures := m.cardFailures[card]; failures >= cardFailureLimit {
+			fmt.Fprintf(w, "monitor: card %d not polled after %d failures\n", card, failures)
+		}
+	}
+	if m.lastState == nil {
+		fmt.Fprintln(w, "monitor: no state read yet")
+		return
+	}
+	cardIDs := make([]uint, 0, len(m.lastState.Cards))
+	for id := range m.lastState.Cards {
+		cardIDs = append(cardIDs, id)
+	}
+	slices.Sort(cardIDs)
+	for _, id := range cardIDs {
+		controls := m.lastState.Cards[id].Controls
+		names := make([]string, 0, len(controls))
+		for name := range controls {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			state := controls[name]
+			fmt.Fprintf(w, "monitor: card %d %q volume=%v mute=%t\n", id, name, state.Volume, state.Mute)
+		}
+	}
+}
+
 func (m *Monitor) monitorLoop() {
 	defer m.wg.Done()
 
 	log.Printf("ALSA monitor loop started")
 
-	ticker := time.NewTicker(100 * time.Millisecond)
-	defer ticker.Stop()
+	m.mu.Lock()
+	interval := m.currentPollInterval(time.Now())
+	m.mu.Unlock()
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
 
 	for {
 		select {
-		case <-ticker.C:
-			currentState := m.getCurrentState()
-			if currentState == nil {
-				continue
+		case <-m.activity:
+			// Coming out of idle: poll now instead of waiting out the
+			// slow interval.
+			m.mu.Lock()
+			fast := m.currentPollInterval(time.Now())
+			m.mu.Unlock()
+			if fast < interval {
+				interval = fast
+				timer.Reset(0)
 			}
 
+		case <-m.resumed:
+			// A bulk operation finished: report its consolidated result
+			// now rather than at the next tick.
+			timer.Reset(0)
+
+		case <-m.cardsHint:
+			timer.Reset(0)
+
+		case <-timer.C:
 			m.mu.Lock()
-			lastState := m.lastState
-			changed, delta := m.computeDelta(currentState, lastState)
-			if changed {
-				clients := m.hub.ClientCount()
-				log.Printf("ALSA state changed, broadcasting delta to %d clients", clients)
-				m.lastState = currentState
-				m.mu.Unlock()
-				m.broadcastDelta(delta)
-			} else {
-				m.mu.Unlock()
-			}
+			interval = m.currentPollInterval(time.Now())
+			m.mu.Unlock()
+			timer.Reset(interval)
+
+			m.tick()
 
 		case <-m.stopCh:
 			log.Printf("ALSA monitor: stop signal received")
@@ -132,6 +391,89 @@ func (m *Monitor) monitorLoop() {
 	}
 }
 
+// tick polls unless the monitor is suspended.
+func (m *Monitor) tick() {
+	m.pollMu.Lock()
+	defer m.pollMu.Unlock()
+
+	m.mu.Lock()
+	suspended := m.suspended > 0
+	m.mu.Unlock()
+	if !suspended {
+		m.poll()
+	}
+}
+
+// poll reads the mixer and broadcasts whatever changed since the last poll.
+func (m *Monitor) poll() {
+	currentState := m.getCurrentState()
+	m.mu.Lock()
+	if currentState == nil {
+		m.failures++
+		m.mu.Unlock()
+		return
+	}
+	m.failures = 0
+	m.lastPoll = time.Now()
+
+	lastState := m.lastState
+	cardsChanged, controlsChanged := topologyChanged(currentState, lastState)
+	onTopologyChange := m.onTopologyChange
+	onChange := m.onChange
+	changed, delta := m.computeDelta(currentState, lastState)
+	if !changed && !cardsChanged && !controlsChanged {
+		m.mu.Unlock()
+		return
+	}
+	if changed {
+		now := time.Now()
+		delta = m.holdLocalChanges(delta, currentState, lastState, now)
+		m.recordChanges(delta, now)
+	}
+	m.lastState = currentState
+	m.mu.Unlock()
+
+	m.broadcastTopology(cardsChanged, controlsChanged, onTopologyChange)
+	if changed && len(delta.Cards) > 0 {
+		clients := m.hub.ClientCount()
+		log.Printf("ALSA state changed, broadcasting delta to %d clients", clients)
+		m.broadcastDelta(delta)
+		if onChange != nil {
+			onChange(delta)
+		}
+	}
+}
+
+// Suspend stops the monitor broadcasting mixer changes until the returned
+// resume function is called, so that a bulk operation setting many controls
+// reaches clients as one consolidated update instead of a series of partial
+// states. Suspend waits for a poll in progress to finish, so nothing read
+// before the suspension is broadcast during it. Suspensions nest; the
+// monitor polls as soon as the last one ends.
+func (m *Monitor) Suspend() (resume func()) {
+	m.mu.Lock()
+	m.suspended++
+	m.mu.Unlock()
+	m.pollMu.Lock()
+	m.pollMu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			m.mu.Lock()
+			m.suspended--
+			last := m.suspended == 0
+			m.mu.Unlock()
+			if last {
+				select {
+				case m.resumed <- struct{}{}:
+				default:
+				}
+			}
+		})
+	}
+}
+
 func (m *Monitor) configWatcherLoop() {
 	defer m.wg.Done()
 
@@ -141,6 +483,15 @@ func (m *Monitor) configWatcherLoop() {
 			if !ok {
 				return
 			}
+			m.mu.Lock()
+			cardsDir := m.cardsDir
+			m.mu.Unlock()
+			if cardsDir != "" && filepath.Dir(event.Name) == cardsDir {
+				if event.Op&(fsnotify.Create|fsnotify.Remove|fsnotify.Rename) != 0 {
+					m.cardsChanged()
+				}
+				continue
+			}
 			if event.Op&fsnotify.Write == fsnotify.Write || event.Op&fsnotify.Create == fsnotify.Create {
 				log.Printf("ALSA config file changed: %s", event.Name)
 				if m.hub != nil {
@@ -167,16 +518,43 @@ func (m *Monitor) getCurrentState() *StateSnapshot {
 		return nil
 	}
 
+	cardIDs := make([]uint, 0, len(cards))
+	for _, card := range cards {
+		cardIDs = append(cardIDs, card.ID)
+	}
+	slices.Sort(cardIDs)
+
+	m.mu.Lock()
+	filter := m.cardFilter
+	// A card that was unplugged and plugged back in may work again.
+	// Disabled cards are left out of the snapshot, so only the raw card
+	// list shows that happening.
+	if m.cardIDs != nil && !slices.Equal(cardIDs, m.cardIDs) && len(m.cardFailures) > 0 {
+		log.Printf("ALSA card list changed; polling every card again")
+		m.cardFailures = nil
+	}
+	m.cardIDs = cardIDs
+	m.mu.Unlock()
+
 	snapshot := &StateSnapshot{
-		Cards: make(map[uint]CardState),
+		Cards:  make(map[uint]CardState),
+		listed: []uint{},
 	}
 
 	for _, card := range cards {
+		if filter != nil && !filter(card) {
+			continue
+		}
+		snapshot.listed = append(snapshot.listed, card.ID)
+		if m.cardDisabled(card.ID) {
+			continue
+		}
 		controls, err := m.mixer.ListControls(card.ID)
 		if err != nil {
-			log.Printf("Failed to list controls for card %d: %v", card.ID, err)
+			m.noteCardFailure(card.ID, err)
 			continue
 		}
+		m.noteCardSuccess(card.ID)
 
 		cardState := CardState{
 			Controls: make(map[string]ControlState),
@@ -214,7 +592,48 @@ func (m *Monitor) getCurrentState() *StateSnapshot {
 	return snapshot
 }
 
-// computeDelta compares current and last state, returning only what changed
+// cardDisabled reports whether card has failed too often to be polled.
+func (m *Monitor) cardDisabled(card uint) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.cardFailures[card] >= cardFailureLimit
+}
+
+// noteCardFailure counts a failure to list card's controls. Each failure
+// is logged until the card reaches cardFailureLimit, which is logged once.
+func (m *Monitor) noteCardFailure(card uint, err error) {
+	m.mu.Lock()
+	if m.cardFailures == nil {
+		m.cardFailures = make(map[uint]int)
+	}
+	m.cardFailures[card]++
+	failures := m.cardFailures[card]
+	m.mu.Unlock()
+
+	log.Printf("Failed to list controls for card %d: %v", card, err)
+	if failures == cardFailureLimit {
+		log.Printf("ALSA monitor: card %d failed %d times in a row; not polling it until the card list changes or a rescan", card, failures)
+	}
+}
+
+// noteCardSuccess resets card's failure count.
+func (m *Monitor) noteCardSuccess(card uint) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.cardFailures, card)
+}
+
+// resetCardFailures polls every card again, including those that failed
+// too often.
+func (m *Monitor) resetCardFailures() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cardFailures = nil
+}
+
+// computeDelta compares current and last state, returning only what changed.
+// Volume changes below the threshold are dropped from the delta and reset
+// to their last value in current.
 func (m *Monitor) computeDelta(current, last *StateSnapshot) (bool, *StateSnapshot) {
 	if last == nil {
 		return true, current
@@ -249,7 +668,7 @@ func (m *Monitor) computeDelta(current, last *StateSnapshot) (bool, *StateSnapsh
 			volumeChanged := len(currentControl.Volume) != len(lastControl.Volume)
 			if !volumeChanged {
 				for i, v := range currentControl.Volume {
-					if i >= len(lastControl.Volume) || v != lastControl.Volume[i] {
+					if diff := abs(v - lastControl.Volume[i]); diff > 0 && diff >= m.volumeThreshold {
 						volumeChanged = true
 						break
 					}
@@ -258,6 +677,13 @@ func (m *Monitor) computeDelta(current, last *StateSnapshot) (bool, *StateSnapsh
 
 			muteChanged := currentControl.Mute != lastControl.Mute
 
+			// Keep comparing against the last reported volume so a slow
+			// drift is still reported once it adds up to the threshold.
+			if !volumeChanged {
+				currentControl.Volume = lastControl.Volume
+				currentCard.Controls[controlName] = currentControl
+			}
+
 			if volumeChanged || muteChanged {
 				cardDelta.Controls[controlName] = currentControl
 				cardHasChanges = true
@@ -277,10 +703,204 @@ func (m *Monitor) computeDelta(current, last *StateSnapshot) (bool, *StateSnapsh
 	return true, delta
 }
 
+// NoteLocalChange records that a control was just changed through the HTTP
+// API. Monitor updates for it are held back for localChangeWindow. It also
+// counts as client activity.
+func (m *Monitor) NoteLocalChange(cardID uint, control string) {
+	m.NoteActivity()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.localChanges == nil {
+		m.localChanges = make(map[string]time.Time)
+	}
+	m.localChanges[localChangeKey(cardID, control)] = time.Now()
+}
+
+// localChangeKey identifies a control for NoteLocalChange. A volume control
+// and its switch share a key, since handlers report both under the volume name.
+func localChangeKey(cardID uint, control string) string {
+	return fmt.Sprintf("%d|%s", cardID, strings.Replace(control, " Switch", " Volume", 1))
+}
+
+// holdLocalChanges drops from delta any control changed locally within
+// localChangeWindow of now. The held controls keep their last values in
+// current, so if the hardware settled on something other than what the
+// handler broadcast, that is reported on the first tick after the window.
+// Must be called with m.mu held.
+func (m *Monitor) holdLocalChanges(delta, current, last *StateSnapshot, now time.Time) *StateSnapshot {
+	for key, at := range m.localChanges {
+		if now.Sub(at) >= localChangeWindow {
+			delete(m.localChanges, key)
+		}
+	}
+	if len(m.localChanges) == 0 || last == nil {
+		return delta
+	}
+
+	for cardID, cardDelta := range delta.Cards {
+		lastCard, exists := last.Cards[cardID]
+		if !exists {
+			continue
+		}
+		for controlName := range cardDelta.Controls {
+			if _, held := m.localChanges[localChangeKey(cardID, controlName)]; !held {
+				continue
+			}
+			lastControl, exists := lastCard.Controls[controlName]
+			if !exists {
+				continue
+			}
+			delete(cardDelta.Controls, controlName)
+			current.Cards[cardID].Controls[controlName] = lastControl
+		}
+		if len(cardDelta.Controls) == 0 {
+			delete(delta.Cards, cardID)
+		}
+	}
+
+	return delta
+}
+
+// controlKey identifies a control across cards.
+func controlKey(cardID uint, control string) string {
+	return fmt.Sprintf("%d|%s", cardID, control)
+}
+
+// recordChanges notes now as the last-change time of every control in delta.
+// Must be called with m.mu held.
+func (m *Monitor) recordChanges(delta *StateSnapshot, now time.Time) {
+	if m.changedAt == nil {
+		m.changedAt = make(map[string]time.Time)
+	}
+	for cardID, card := range delta.Cards {
+		for controlName := range card.Controls {
+			m.changedAt[controlKey(cardID, controlName)] = now
+		}
+	}
+}
+
+// StateSince returns the controls, from the monitor's latest reading, whose
+// state changed after since. A zero since returns every control.
+func (m *Monitor) StateSince(since time.Time) *StateSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := &StateSnapshot{Cards: make(map[uint]CardState)}
+	if m.lastState == nil {
+		return result
+	}
+
+	for cardID, card := range m.lastState.Cards {
+		changed := CardState{Controls: make(map[string]ControlState)}
+		for controlName, state := range card.Controls {
+			if since.IsZero() || m.changedAt[controlKey(cardID, controlName)].After(since) {
+				changed.Controls[controlName] = state
+			}
+		}
+		if len(changed.Controls) > 0 {
+			result.Cards[cardID] = changed
+		}
+	}
+
+	return result
+}
+
+// cardsChanged asks the monitor loop to poll now after a device node was
+// added or removed. The poll decides whether the cards really changed.
+func (m *Monitor) cardsChanged() {
+	log.Printf("ALSA device nodes changed, polling")
+	select {
+	case m.cardsHint <- struct{}{}:
+	default:
+	}
+}
+
+// Rescan polls straight away, after giving cards the monitor stopped
+// polling after repeated failures another chance. Like any poll it
+// broadcasts what changed since the last one. While the monitor is
+// suspended, the poll that ends the suspension does this instead.
+func (m *Monitor) Rescan() {
+	m.resetCardFailures()
+	m.tick()
+}
+
+// topologyChanged reports whether the card list, or the control list of any
+// card read in both snapshots, differs between last and current. The card
+// list is what ListCards returned, so a card whose controls could not be
+// read in one of the snapshots changes neither. The first snapshot
+// (last == nil) is the baseline and never counts as a change.
+func topologyChanged(current, last *StateSnapshot) (cardsChanged, controlsChanged bool) {
+	if current == nil || last == nil {
+		return false, false
+	}
+
+	cardsChanged = !slices.Equal(current.listedCards(), last.listedCards())
+	for cardID, currentCard := range current.Cards {
+		lastCard, exists := last.Cards[cardID]
+		if !exists {
+			continue
+		}
+		if len(currentCard.Controls) != len(lastCard.Controls) {
+			controlsChanged = true
+			continue
+		}
+		for controlName := range currentCard.Controls {
+			if _, exists := lastCard.Controls[controlName]; !exists {
+				controlsChanged = true
+				break
+			}
+		}
+	}
+
+	return cardsChanged, controlsChanged
+}
+
+// listedCards returns the sorted IDs of the cards listed when s was read,
+// falling back to the cards in s for snapshots built elsewhere.
+func (s *StateSnapshot) listedCards() []uint {
+	if s.listed != nil {
+		return slices.Sorted(slices.Values(s.listed))
+	}
+	return slices.Sorted(maps.Keys(s.Cards))
+}
+
+// broadcastTopology announces card-list and control-list changes to clients
+// and notifies the registered topology callback, if any.
+func (m *Monitor) broadcastTopology(cardsChanged, controlsChanged bool, callback func()) {
+	if !cardsChanged && !controlsChanged {
+		return
+	}
+
+	if cardsChanged {
+		log.Printf("ALSA card list changed")
+		m.hub.Broadcast(sse.Event{Type: "card-list-change", Data: map[string]interface{}{
+			"timestamp": time.Now().UnixMilli(),
+		}})
+	}
+	if controlsChanged {
+		log.Printf("ALSA control list changed")
+		m.hub.Broadcast(sse.Event{Type: "controls-changed", Data: map[string]interface{}{
+			"timestamp": time.Now().UnixMilli(),
+		}})
+	}
+
+	if callback != nil {
+		callback()
+	}
+}
+
 func (m *Monitor) broadcastDelta(delta *StateSnapshot) {
 	m.hub.Broadcast(sse.Event{Type: "mixer-update", Data: map[string]interface{}{
 		"state":     delta,
 		"source":    "monitor",
-		"timestamp": time.Now().Unix(),
+		"timestamp": time.Now().UnixMilli(),
 	}})
 }
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
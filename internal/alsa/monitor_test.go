@@ -0,0 +1,764 @@
+package alsa
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/user/alsamixer-web/internal/sse"
+)
+
+// fakeHub records broadcast events for assertions.
+type fakeHub struct {
+	mu     sync.Mutex
+	events []sse.Event
+}
+
+func (h *fakeHub) ClientCount() int { return 0 }
+
+func (h *fakeHub) Broadcast(event sse.Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.events = append(h.events, event)
+}
+
+// reset forgets the events broadcast so far, such as a baseline poll's.
+func (h *fakeHub) reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.events = nil
+}
+
+func (h *fakeHub) eventTypes() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	types := make([]string, 0, len(h.events))
+	for _, e := range h.events {
+		types = append(types, e.Type)
+	}
+	return types
+}
+
+// fakeReader is a Reader with a single card and no controls.
+type fakeReader struct {
+	mu        sync.Mutex
+	listCards int
+	plugged   []Card // listed after card 1
+}
+
+func (r *fakeReader) ListCards() ([]Card, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.listCards++
+	return append([]Card{{ID: 1, Name: "USB"}}, r.plugged...), nil
+}
+
+func (r *fakeReader) ListControls(card uint) ([]Control, error)          { return nil, nil }
+func (r *fakeReader) GetVolume(card uint, control string) ([]int, error) { return nil, nil }
+func (r *fakeReader) GetMute(card uint, control string) (bool, error)    { return false, nil }
+
+// snapshot builds a state in which every card in cards was listed and read.
+func snapshot(cards map[uint][]string) *StateSnapshot {
+	s := &StateSnapshot{Cards: make(map[uint]CardState), listed: []uint{}}
+	for id, names := range cards {
+		s.listed = append(s.listed, id)
+		cs := CardState{Controls: make(map[string]ControlState)}
+		for _, name := range names {
+			cs.Controls[name] = ControlState{Volume: []int{50}}
+		}
+		s.Cards[id] = cs
+	}
+	return s
+}
+
+func TestTopologyChanged(t *testing.T) {
+	base := snapshot(map[uint][]string{0: {"Master Playback Volume"}})
+	both := snapshot(map[uint][]string{0: {"Master Playback Volume"}, 1: {"PCM"}})
+	unreadable := snapshot(map[uint][]string{0: {"Master Playback Volume"}})
+	unreadable.listed = []uint{0, 1}
+
+	tests := []struct {
+		name            string
+		current, last   *StateSnapshot
+		cardsChanged    bool
+		controlsChanged bool
+	}{
+		{"baseline", base, nil, false, false},
+		{"unchanged", base, base, false, false},
+		{"card added", both, base, true, false},
+		{"card removed", base, both, true, false},
+		{"card unreadable", unreadable, both, false, false},
+		{"card readable again", both, unreadable, false, false},
+		{"control renamed", snapshot(map[uint][]string{0: {"Speaker Playback Volume"}}), base, false, true},
+		{"control added", snapshot(map[uint][]string{0: {"Master Playback Volume", "PCM"}}), base, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cards, controls := topologyChanged(tt.current, tt.last)
+			if cards != tt.cardsChanged || controls != tt.controlsChanged {
+				t.Errorf("topologyChanged() = (%v, %v), want (%v, %v)", cards, controls, tt.cardsChanged, tt.controlsChanged)
+			}
+		})
+	}
+}
+
+func TestBroadcastTopology(t *testing.T) {
+	hub := &fakeHub{}
+	m := &Monitor{hub: hub}
+
+	called := 0
+	before := time.Now().UnixMilli()
+	m.broadcastTopology(false, true, func() { called++ })
+
+	if called != 1 {
+		t.Errorf("expected topology callback to be invoked once, got %d", called)
+	}
+	types := hub.eventTypes()
+	if len(types) != 1 || types[0] != "controls-changed" {
+		t.Fatalf("expected a single controls-changed event, got %v", types)
+	}
+	if ts, _ := hub.events[0].Data.(map[string]interface{})["timestamp"].(int64); ts < before {
+		t.Errorf("expected a unix millisecond timestamp, got %d", ts)
+	}
+
+	m.broadcastTopology(false, false, func() { called++ })
+	if called != 1 {
+		t.Errorf("expected no callback without a topology change, got %d calls", called)
+	}
+}
+
+func TestCheckWatchPaths(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "asound.conf")
+	if err := os.WriteFile(existing, []byte("defaults.pcm.card 0\n"), 0o644); err != nil {
+		t.Fatalf("writing config file: %v", err)
+	}
+	missing := filepath.Join(dir, "missing.conf")
+
+	statuses := checkWatchPaths([]string{existing, missing})
+	if len(statuses) != 2 {
+		t.Fatalf("expected 2 statuses, got %d", len(statuses))
+	}
+
+	if statuses[0].Reason != "" {
+		t.Errorf("expected %s to be watchable, got reason %q", existing, statuses[0].Reason)
+	}
+
+	if statuses[1].Reason == "" {
+		t.Fatalf("expected %s to be reported as not watchable", missing)
+	}
+	warning := statuses[1].warning()
+	if !strings.Contains(warning, missing) || !strings.Contains(warning, "does not exist") {
+		t.Errorf("expected warning to identify the missing file, got %q", warning)
+	}
+}
+
+func TestPollDetectsHotplug(t *testing.T) {
+	reader := &fakeReader{}
+	hub := &fakeHub{}
+	m := NewMonitor(reader, hub, "")
+	t.Cleanup(m.Stop)
+	topologyChanges := 0
+	m.OnTopologyChange(func() { topologyChanges++ })
+	m.Rescan()
+
+	// A second USB card is plugged in.
+	reader.mu.Lock()
+	reader.plugged = []Card{{ID: 2, Name: "Headset"}}
+	reader.mu.Unlock()
+	m.poll()
+
+	countCardChanges := func() int {
+		n := 0
+		for _, typ := range hub.eventTypes() {
+			if typ == "card-list-change" {
+				n++
+			}
+		}
+		return n
+	}
+	if got := countCardChanges(); got != 1 {
+		t.Fatalf("expected a single card-list-change broadcast, got %v", hub.eventTypes())
+	}
+	if topologyChanges != 1 {
+		t.Errorf("expected one topology callback, got %d", topologyChanges)
+	}
+
+	// And removed again.
+	reader.mu.Lock()
+	reader.plugged = nil
+	reader.mu.Unlock()
+	m.poll()
+	m.poll()
+
+	if got := countCardChanges(); got != 2 {
+		t.Errorf("expected the removal to be reported once, got %v", hub.eventTypes())
+	}
+}
+
+func TestWatchCardsPollsOnChange(t *testing.T) {
+	dev := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dev, "controlC1"), nil, 0o644); err != nil {
+		t.Fatalf("creating device node: %v", err)
+	}
+
+	reader := &fakeReader{}
+	hub := &fakeHub{}
+	m := NewMonitor(reader, hub, "")
+	if err := m.WatchCards(dev); err != nil {
+		t.Fatalf("WatchCards() error = %v", err)
+	}
+	// Poll rarely enough that only the watch can explain a prompt update.
+	m.SetPollInterval(time.Hour, 0)
+	m.Rescan()
+	hub.reset()
+	m.Start()
+	t.Cleanup(m.Stop)
+
+	waitForCardChanges := func(want int) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for {
+			got := 0
+			for _, typ := range hub.eventTypes() {
+				if typ == "card-list-change" {
+					got++
+				}
+			}
+			if got >= want {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for card-list-change %d, got %v", want, hub.eventTypes())
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	// A second USB card is plugged in and udev creates its device node.
+	reader.mu.Lock()
+	reader.plugged = []Card{{ID: 2, Name: "Headset"}}
+	reader.mu.Unlock()
+	node := filepath.Join(dev, "controlC2")
+	if err := os.WriteFile(node, nil, 0o644); err != nil {
+		t.Fatalf("creating device node: %v", err)
+	}
+	waitForCardChanges(1)
+
+	m.mu.Lock()
+	_, ok := m.lastState.Cards[2]
+	m.mu.Unlock()
+	if !ok {
+		t.Error("expected the poll to become the new baseline")
+	}
+
+	// The card is removed again, and so is its node.
+	reader.mu.Lock()
+	reader.plugged = nil
+	reader.mu.Unlock()
+	if err := os.Remove(node); err != nil {
+		t.Fatalf("removing device node: %v", err)
+	}
+	waitForCardChanges(2)
+}
+
+func TestHoldLocalChanges(t *testing.T) {
+	last := snapshot(map[uint][]string{0: {"Master Playback Volume", "Master Playback Switch", "PCM Playback Volume"}})
+
+	// The handler set Master to 60 and the hardware snapped it to 59; PCM
+	// was changed externally at the same time.
+	reading := func() *StateSnapshot {
+		current := snapshot(map[uint][]string{0: {"Master Playback Volume", "Master Playback Switch", "PCM Playback Volume"}})
+		current.Cards[0].Controls["Master Playback Volume"] = ControlState{Volume: []int{59}}
+		current.Cards[0].Controls["Master Playback Switch"] = ControlState{Volume: []int{50}, Mute: true}
+		current.Cards[0].Controls["PCM Playback Volume"] = ControlState{Volume: []int{20}}
+		return current
+	}
+
+	m := &Monitor{}
+	m.NoteLocalChange(0, "Master Playback Volume")
+	now := time.Now()
+
+	current := reading()
+	_, delta := m.computeDelta(current, last)
+	delta = m.holdLocalChanges(delta, current, last, now)
+
+	controls := delta.Cards[0].Controls
+	if _, ok := controls["Master Playback Volume"]; ok {
+		t.Error("expected locally changed volume to be held back within the window")
+	}
+	if _, ok := controls["Master Playback Switch"]; ok {
+		t.Error("expected the matching switch to be held back within the window")
+	}
+	if _, ok := controls["PCM Playback Volume"]; !ok {
+		t.Error("expected unrelated external change to be broadcast")
+	}
+	if got := current.Cards[0].Controls["Master Playback Volume"].Volume[0]; got != 50 {
+		t.Errorf("expected held control to keep its last value in the new baseline, got %d", got)
+	}
+
+	// Once the window has passed the settled hardware value is reported.
+	current = reading()
+	_, delta = m.computeDelta(current, last)
+	delta = m.holdLocalChanges(delta, current, last, now.Add(localChangeWindow))
+
+	if _, ok := delta.Cards[0].Controls["Master Playback Volume"]; !ok {
+		t.Error("expected held control to be broadcast after the window")
+	}
+}
+
+func TestVolumeThreshold(t *testing.T) {
+	m := &Monitor{}
+	m.SetVolumeThreshold(2)
+
+	reading := func(volume int, muted bool) *StateSnapshot {
+		current := snapshot(map[uint][]string{0: {"Capture Volume"}})
+		current.Cards[0].Controls["Capture Volume"] = ControlState{Volume: []int{volume}, Mute: muted}
+		return current
+	}
+	last := reading(50, false)
+
+	if changed, _ := m.computeDelta(reading(51, false), last); changed {
+		t.Error("expected a 1% change under a 2% threshold not to be broadcast")
+	}
+	if changed, _ := m.computeDelta(reading(53, false), last); !changed {
+		t.Error("expected a 3% change over a 2% threshold to be broadcast")
+	}
+	if changed, _ := m.computeDelta(reading(51, true), last); !changed {
+		t.Error("expected a mute change to be broadcast regardless of threshold")
+	}
+
+	// Small steps are measured from the last reported value, so a drift is
+	// reported once it adds up.
+	current := reading(51, false)
+	m.computeDelta(current, last)
+	if got := current.Cards[0].Controls["Capture Volume"].Volume[0]; got != 50 {
+		t.Errorf("expected ignored change to keep the last reported value, got %d", got)
+	}
+	if changed, _ := m.computeDelta(reading(52, false), current); !changed {
+		t.Error("expected accumulated drift to be broadcast")
+	}
+}
+
+func TestAdaptivePollInterval(t *testing.T) {
+	m := &Monitor{}
+	if got := m.currentPollInterval(time.Now()); got != defaultPollInterval {
+		t.Fatalf("expected default interval %v, got %v", defaultPollInterval, got)
+	}
+
+	m.SetPollInterval(100*time.Millisecond, 2*time.Second)
+	if got := m.currentPollInterval(time.Now()); got != 2*time.Second {
+		t.Fatalf("expected idle interval with no activity, got %v", got)
+	}
+
+	m.NoteLocalChange(0, "Master Playback Volume")
+	now := time.Now()
+	if got := m.currentPollInterval(now); got != 100*time.Millisecond {
+		t.Errorf("expected fast interval right after activity, got %v", got)
+	}
+	if got := m.currentPollInterval(now.Add(idleAfter)); got != 2*time.Second {
+		t.Errorf("expected idle interval after %v without activity, got %v", idleAfter, got)
+	}
+
+	m.NoteActivity()
+	if got := m.currentPollInterval(time.Now()); got != 100*time.Millisecond {
+		t.Errorf("expected a new connection to restore the fast interval, got %v", got)
+	}
+
+	// Without a longer idle interval the monitor never slows down.
+	m.SetPollInterval(250*time.Millisecond, 0)
+	if got := m.currentPollInterval(now.Add(time.Hour)); got != 250*time.Millisecond {
+		t.Errorf("expected fixed interval when idle polling is disabled, got %v", got)
+	}
+}
+
+func TestStateSince(t *testing.T) {
+	m := &Monitor{}
+	start := time.Unix(1700000000, 0)
+
+	baseline := snapshot(map[uint][]string{0: {"Master Playback Volume", "PCM Playback Volume"}})
+	m.recordChanges(baseline, start)
+	m.lastState = baseline
+
+	later := start.Add(time.Second)
+	m.recordChanges(snapshot(map[uint][]string{0: {"PCM Playback Volume"}}), later)
+
+	changed := m.StateSince(start.Add(time.Millisecond))
+	controls := changed.Cards[0].Controls
+	if _, ok := controls["PCM Playback Volume"]; !ok {
+		t.Error("expected control changed after the timestamp to be included")
+	}
+	if _, ok := controls["Master Playback Volume"]; ok {
+		t.Error("expected control unchanged since the timestamp to be excluded")
+	}
+
+	if none := m.StateSince(later); len(none.Cards) != 0 {
+		t.Errorf("expected no changes after the latest change, got %v", none.Cards)
+	}
+	if all := m.StateSince(time.Time{}); len(all.Cards[0].Controls) != 2 {
+		t.Errorf("expected a zero timestamp to return every control, got %v", all.Cards)
+	}
+}
+
+// volumeReader is a Reader with one card whose integer controls hold the
+// volumes in its map.
+type volumeReader struct {
+	mu      sync.Mutex
+	volumes map[string]int
+}
+
+func (r *volumeReader) set(control string, volume int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.volumes[control] = volume
+}
+
+func (r *volumeReader) ListCards() ([]Card, error) { return []Card{{ID: 0, Name: "PCH"}}, nil }
+
+func (r *volumeReader) ListControls(card uint) ([]Control, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	controls := make([]Control, 0, len(r.volumes))
+	for name := range r.volumes {
+		controls = append(controls, Control{Name: name, Type: "integer"})
+	}
+	return controls, nil
+}
+
+func (r *volumeReader) GetVolume(card uint, control string) ([]int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return []int{r.volumes[control]}, nil
+}
+
+func (r *volumeReader) GetMute(card uint, control string) (bool, error) { return false, nil }
+
+func TestSuspendConsolidatesBroadcasts(t *testing.T) {
+	reader := &volumeReader{volumes: map[string]int{
+		"Master Playback Volume":  50,
+		"PCM Playback Volume":     50,
+		"Speaker Playback Volume": 50,
+	}}
+	hub := &fakeHub{}
+	m := NewMonitor(reader, hub, "")
+	m.SetPollInterval(5*time.Millisecond, 0)
+	m.Rescan()
+	hub.reset()
+	m.wg.Add(1)
+	go m.monitorLoop()
+	t.Cleanup(m.Stop)
+
+	// A bulk operation sets each control in turn, slowly enough for
+	// several polls to see the partial states.
+	resume := m.Suspend()
+	for _, control := range []string{"Master Playback Volume", "PCM Playback Volume", "Speaker Playback Volume"} {
+		reader.set(control, 20)
+		time.Sleep(20 * time.Millisecond)
+	}
+	if types := hub.eventTypes(); len(types) != 0 {
+		t.Fatalf("expected no broadcasts while suspended, got %v", types)
+	}
+	resume()
+	resume() // resuming twice is harmless
+
+	deadline := time.Now().Add(time.Second)
+	for len(hub.eventTypes()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the consolidated broadcast")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	if len(hub.events) != 1 || hub.events[0].Type != "mixer-update" {
+		t.Fatalf("expected a single mixer-update, got %v", hub.events)
+	}
+	delta := hub.events[0].Data.(map[string]interface{})["state"].(*StateSnapshot)
+	if got := len(delta.Cards[0].Controls); got != 3 {
+		t.Errorf("expected all 3 changed controls in one update, got %d: %+v", got, delta.Cards[0].Controls)
+	}
+}
+
+func TestRescanReportsPendingChanges(t *testing.T) {
+	reader := &volumeReader{volumes: map[string]int{
+		"Master Playback Volume": 50,
+		"PCM Playback Volume":    50,
+	}}
+	hub := &fakeHub{}
+	m := NewMonitor(reader, hub, "")
+	t.Cleanup(m.Stop)
+	m.Rescan()
+	hub.reset()
+
+	// Changed outside the app since the last poll.
+	before := time.Now()
+	reader.set("PCM Playback Volume", 20)
+	m.Rescan()
+
+	hub.mu.Lock()
+	events := hub.events
+	hub.mu.Unlock()
+	if len(events) != 1 || events[0].Type != "mixer-update" {
+		t.Fatalf("expected the rescan to broadcast the pending change, got %v", events)
+	}
+	delta := events[0].Data.(map[string]interface{})["state"].(*StateSnapshot)
+	if got := delta.Cards[0].Controls["PCM Playback Volume"].Volume; len(got) != 1 || got[0] != 20 {
+		t.Errorf("expected PCM at 20 in the update, got %+v", delta.Cards[0].Controls)
+	}
+	if changed := m.StateSince(before); len(changed.Cards[0].Controls) != 1 {
+		t.Errorf("expected the rescan to record the change for ?since=, got %v", changed.Cards)
+	}
+}
+
+func TestRescanKeepsStateWhenReadFails(t *testing.T) {
+	reader := &flakyListReader{volumeReader: volumeReader{volumes: map[string]int{"Master Playback Volume": 50}}}
+	m := NewMonitor(reader, &fakeHub{}, "")
+	t.Cleanup(m.Stop)
+	m.Rescan()
+
+	reader.fail = true
+	m.Rescan()
+
+	if state := m.StateSince(time.Time{}); len(state.Cards[0].Controls) != 1 {
+		t.Errorf("expected a failed rescan to keep the last state, got %v", state.Cards)
+	}
+}
+
+// flakyListReader is a volumeReader whose ListCards fails while fail is set.
+type flakyListReader struct {
+	volumeReader
+	fail bool
+}
+
+func (r *flakyListReader) ListCards() ([]Card, error) {
+	if r.fail {
+		return nil, errors.New("no cards")
+	}
+	return r.volumeReader.ListCards()
+}
+
+func TestWriteStateWhileLocked(t *testing.T) {
+	m := NewMonitor(&fakeReader{}, &fakeHub{}, "")
+	t.Cleanup(m.Stop)
+
+	m.mu.Lock()
+	var buf bytes.Buffer
+	m.WriteState(&buf)
+	m.mu.Unlock()
+
+	if got := buf.String(); got != "monitor state locked\n" {
+		t.Errorf("expected WriteState not to wait for a held lock, got %q", got)
+	}
+}
+
+// blockingReader is a volumeReader whose ListCards announces itself on
+// entered and then waits for release.
+type blockingReader struct {
+	volumeReader
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (r *blockingReader) ListCards() ([]Card, error) {
+	r.entered <- struct{}{}
+	<-r.release
+	return r.volumeReader.ListCards()
+}
+
+func TestSuspendWaitsForPoll(t *testing.T) {
+	reader := &blockingReader{
+		volumeReader: volumeReader{volumes: map[string]int{"Master Playback Volume": 50}},
+		entered:      make(chan struct{}),
+		release:      make(chan struct{}),
+	}
+	m := NewMonitor(reader, &fakeHub{}, "")
+	t.Cleanup(m.Stop)
+
+	go m.tick()
+	<-reader.entered
+
+	suspended := make(chan func())
+	go func() { suspended <- m.Suspend() }()
+	select {
+	case <-suspended:
+		t.Fatal("expected Suspend to wait for the poll in progress")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(reader.release)
+	select {
+	case resume := <-suspended:
+		resume()
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for Suspend once the poll finished")
+	}
+}
+
+// flakyCardReader has a healthy card 0 and a card 1 whose controls can
+// only be listed once fixed is set. Card 1 is listed unless unplugged is set.
+type flakyCardReader struct {
+	volumeReader
+	failedLists int
+	unplugged   bool
+	fixed       bool
+}
+
+func (r *flakyCardReader) ListCards() ([]Card, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.unplugged {
+		return []Card{{ID: 0, Name: "PCH"}}, nil
+	}
+	return []Card{{ID: 0, Name: "PCH"}, {ID: 1, Name: "USB"}}, nil
+}
+
+func (r *flakyCardReader) ListControls(card uint) ([]Control, error) {
+	if card == 1 {
+		r.mu.Lock()
+		fixed := r.fixed
+		if !fixed {
+			r.failedLists++
+		}
+		r.mu.Unlock()
+		if !fixed {
+			return nil, errors.New("device disconnected")
+		}
+	}
+	return r.volumeReader.ListControls(card)
+}
+
+func TestFailingCardIsSuppressed(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(os.Stdout) })
+
+	reader := &flakyCardReader{volumeReader: volumeReader{volumes: map[string]int{"Master Playback Volume": 0}}}
+	hub := &fakeHub{}
+	m := NewMonitor(reader, hub, "")
+	t.Cleanup(m.Stop)
+	m.Rescan()
+	hub.reset()
+
+	const polls = cardFailureLimit * 3
+	for i := 1; i <= polls; i++ {
+		reader.set("Master Playback Volume", i)
+		m.poll()
+	}
+
+	if reader.failedLists != cardFailureLimit {
+		t.Errorf("expected card 1 to be polled %d times, got %d", cardFailureLimit, reader.failedLists)
+	}
+	if got := strings.Count(logs.String(), "Failed to list controls for card 1"); got != cardFailureLimit {
+		t.Errorf("expected %d logged failures, got %d:\n%s", cardFailureLimit, got, logs.String())
+	}
+	if got := strings.Count(logs.String(), "not polling it"); got != 1 {
+		t.Errorf("expected the card to be reported as suppressed once, got %d", got)
+	}
+
+	updates := 0
+	for _, e := range hub.events {
+		if e.Type == "mixer-update" {
+			updates++
+		}
+	}
+	if updates != polls {
+		t.Errorf("expected the healthy card to update on each of %d polls, got %d", polls, updates)
+	}
+
+	m.Rescan()
+	if reader.failedLists != cardFailureLimit+1 {
+		t.Errorf("expected a rescan to poll card 1 again, got %d attempts", reader.failedLists)
+	}
+}
+
+// flappingCardReader has a healthy card 0 and a card 1 whose controls can
+// only be listed on every other attempt.
+type flappingCardReader struct {
+	volumeReader
+	lists int
+}
+
+func (r *flappingCardReader) ListCards() ([]Card, error) {
+	return []Card{{ID: 0, Name: "PCH"}, {ID: 1, Name: "USB"}}, nil
+}
+
+func (r *flappingCardReader) ListControls(card uint) ([]Control, error) {
+	if card == 1 {
+		r.mu.Lock()
+		r.lists++
+		fail := r.lists%2 == 0
+		r.mu.Unlock()
+		if fail {
+			return nil, errors.New("device busy")
+		}
+	}
+	return r.volumeReader.ListControls(card)
+}
+
+func TestFlappingCardIsNotACardListChange(t *testing.T) {
+	reader := &flappingCardReader{volumeReader: volumeReader{volumes: map[string]int{"Master Playback Volume": 0}}}
+	hub := &fakeHub{}
+	m := NewMonitor(reader, hub, "")
+	t.Cleanup(m.Stop)
+	topologyChanges := 0
+	m.OnTopologyChange(func() { topologyChanges++ })
+	m.Rescan()
+
+	for i := 0; i < 6; i++ {
+		m.poll()
+	}
+
+	for _, typ := range hub.eventTypes() {
+		if typ == "card-list-change" || typ == "controls-changed" {
+			t.Errorf("expected no topology events while card 1 flaps, got %v", hub.eventTypes())
+			break
+		}
+	}
+	if topologyChanges != 0 {
+		t.Errorf("expected no topology callbacks, got %d", topologyChanges)
+	}
+}
+
+func TestReplugRepollsFailingCard(t *testing.T) {
+	reader := &flakyCardReader{volumeReader: volumeReader{volumes: map[string]int{"Master Playback Volume": 0}}}
+	hub := &fakeHub{}
+	m := NewMonitor(reader, hub, "")
+	t.Cleanup(m.Stop)
+	m.Rescan()
+	for i := 0; i < cardFailureLimit; i++ {
+		m.poll()
+	}
+	if !m.cardDisabled(1) {
+		t.Fatalf("expected card 1 to be disabled after %d failures", cardFailureLimit)
+	}
+
+	reader.mu.Lock()
+	reader.unplugged = true
+	reader.mu.Unlock()
+	m.poll()
+
+	reader.mu.Lock()
+	reader.unplugged = false
+	reader.fixed = true
+	reader.mu.Unlock()
+	m.poll()
+
+	if m.cardDisabled(1) {
+		t.Fatal("expected card 1 to be polled again after it was replugged")
+	}
+	m.mu.Lock()
+	_, polled := m.lastState.Cards[1]
+	m.mu.Unlock()
+	if !polled {
+		t.Error("expected the replugged card's controls in the monitor state")
+	}
+}
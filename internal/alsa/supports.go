@@ -0,0 +1,48 @@
+package alsa
+
+// Operation is a change a handler can ask of a control.
+type Operation string
+
+const (
+	OpVolume  Operation = "volume"
+	OpMute    Operation = "mute"
+	OpCapture Operation = "capture"
+)
+
+// CapabilityReader is the part of a mixer that reports what a control
+// can do. *Mixer implements it.
+type CapabilityReader interface {
+	HasPlaybackVolume(card uint, control string) (bool, error)
+	HasPlaybackSwitch(card uint, control string) (bool, error)
+	HasCaptureVolume(card uint, control string) (bool, error)
+	HasCaptureSwitch(card uint, control string) (bool, error)
+}
+
+// SupportsOperation reports whether control on card can perform op,
+// judged by r's capability helpers: volume needs a playback or capture
+// volume, mute a playback or capture switch, and capture a capture switch.
+func SupportsOperation(r CapabilityReader, card uint, control string, op Operation) (bool, error) {
+	var first, second func(uint, string) (bool, error)
+	switch op {
+	case OpVolume:
+		first, second = r.HasPlaybackVolume, r.HasCaptureVolume
+	case OpMute:
+		first, second = r.HasPlaybackSwitch, r.HasCaptureSwitch
+	case OpCapture:
+		first = r.HasCaptureSwitch
+	default:
+		return false, nil
+	}
+
+	ok, err := first(card, control)
+	if ok || err != nil || second == nil {
+		return ok, err
+	}
+	return second(card, control)
+}
+
+// Supports reports whether control on card can perform op, so callers can
+// reject an unsupported request up front instead of failing halfway.
+func (m *Mixer) Supports(card uint, control string, op Operation) (bool, error) {
+	return SupportsOperation(m, card, control, op)
+}
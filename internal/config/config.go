@@ -4,21 +4,229 @@ import (
 	"bytes"
 	"flag"
 	"fmt"
+	"math"
+	"net"
 	"os"
+	"regexp"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
 	Port        int
 	BindAddr    string
+	DualStack   bool // listen on both 0.0.0.0 and ::
 	CardIndex   uint
+	CardSet     bool // CardIndex was given explicitly and overrides the ALSA default
 	LogLevel    string
 	MonitorFile string
+	ReadOnly    bool
+	ExposeCards []string // card indexes or names; empty exposes every card
+	DebugLogs   bool
+	DryRun      bool
+	Shortcuts   map[string][]string // keyboard action -> key names (KeyboardEvent.key)
+	// StrictVolume rejects volumes outside 0-100 instead of clamping them.
+	StrictVolume bool
+	// VolumeThreshold is the smallest volume change, in percent, the
+	// monitor broadcasts. 0 broadcasts every change.
+	VolumeThreshold int
+	// PollInterval is how often the monitor reads the mixer while clients
+	// are active; IdlePollInterval, if longer, is used once they go idle.
+	PollInterval     time.Duration
+	IdlePollInterval time.Duration
+	// HandleIdleTimeout is how long a card's mixer handle stays open
+	// after its last use. 0 reopens it on every read.
+	HandleIdleTimeout time.Duration
+	// DedupeWindow drops a control change broadcast identical to the one
+	// sent for that control less than this long ago. 0 disables it.
+	DedupeWindow time.Duration
+	// CardTrims offsets the volumes of a card, keyed by index, by a number
+	// of percentage points, so that the same percentage sounds alike on
+	// cards of different loudness.
+	CardTrims map[uint]int
+	// Groups are named sets of controls set together by one group volume,
+	// e.g. the front and surround speakers.
+	Groups map[string][]GroupMember
+	// AdminToken enables the /admin endpoints for requests that send it as
+	// a bearer token. Empty disables them.
+	AdminToken string
+	// AllowGetActions enables GET /action/... links that change controls.
+	// They must carry ActionToken, or a token generated at startup if empty.
+	AllowGetActions bool
+	ActionToken     string
+}
+
+// GroupMember is a control driven by a group volume.
+type GroupMember struct {
+	Card    uint
+	Control string
+	// Scale multiplies the group volume for this member; 1 follows it.
+	Scale float64
+}
+
+// redacted replaces secret values in Redacted's output.
+const redacted = "[redacted]"
+
+// Redacted returns a copy of c that is safe to log or serve, with secrets
+// such as the admin token replaced.
+func (c Config) Redacted() Config {
+	if c.AdminToken != "" {
+		c.AdminToken = redacted
+	}
+	if c.ActionToken != "" {
+		c.ActionToken = redacted
+	}
+	return c
+}
+
+// DefaultShortcuts are the keys bound to each keyboard action unless
+// overridden with --shortcut.
+var DefaultShortcuts = map[string][]string{
+	"volume-up":   {"ArrowUp", "ArrowRight"},
+	"volume-down": {"ArrowDown", "ArrowLeft"},
+	"mute":        {"m"},
+	"capture":     {"c"},
+}
+
+// parseShortcut parses an "action=key [key...]" binding.
+func parseShortcut(binding string) (string, []string, error) {
+	action, keys, ok := strings.Cut(binding, "=")
+	action = strings.TrimSpace(action)
+	if !ok || action == "" {
+		return "", nil, fmt.Errorf("invalid shortcut %q: expected action=key", binding)
+	}
+	if _, known := DefaultShortcuts[action]; !known {
+		return "", nil, fmt.Errorf("invalid shortcut %q: unknown action %q", binding, action)
+	}
+	return action, strings.Fields(keys), nil
+}
+
+// parseCardTrim parses a "card=offset" trim, e.g. "1=+10" or "2=-5".
+func parseCardTrim(trim string) (uint, int, error) {
+	card, offset, ok := strings.Cut(trim, "=")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid card trim %q: expected card=offset", trim)
+	}
+	c, err := strconv.ParseUint(strings.TrimSpace(card), 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid card trim %q: card must be an index", trim)
+	}
+	o, err := strconv.Atoi(strings.TrimSpace(offset))
+	if err != nil || o < -100 || o > 100 {
+		return 0, 0, fmt.Errorf("invalid card trim %q: offset must be between -100 and 100", trim)
+	}
+	return uint(c), o, nil
+}
+
+// groupNamePattern keeps group names usable as a URL path segment.
+var groupNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// parseGroup parses a "name=card:control[*scale]+..." group, e.g.
+// "speakers=0:Front+0:Surround*0.8".
+func parseGroup(group string) (string, []GroupMember, error) {
+	name, list, ok := strings.Cut(group, "=")
+	name = strings.TrimSpace(name)
+	if !ok || !groupNamePattern.MatchString(name) {
+		return "", nil, fmt.Errorf("invalid group %q: expected name=card:control+card:control", group)
+	}
+
+	var members []GroupMember
+	for _, member := range strings.Split(list, "+") {
+		spec, scaleStr, scaled := strings.Cut(strings.TrimSpace(member), "*")
+		card, control, ok := strings.Cut(spec, ":")
+		c, err := strconv.ParseUint(strings.TrimSpace(card), 10, 64)
+		control = strings.TrimSpace(control)
+		if !ok || err != nil || control == "" {
+			return "", nil, fmt.Errorf("invalid group %q: member %q must be card:control", group, member)
+		}
+		scale := 1.0
+		if scaled {
+			scale, err = strconv.ParseFloat(strings.TrimSpace(scaleStr), 64)
+			if err != nil || math.IsNaN(scale) || math.IsInf(scale, 0) || scale <= 0 || scale > 10 {
+				return "", nil, fmt.Errorf("invalid group %q: scale %q must be a number above 0 and at most 10", group, scaleStr)
+			}
+		}
+		members = append(members, GroupMember{Card: uint(c), Control: control, Scale: scale})
+	}
+	return name, members, nil
+}
+
+// validateBind normalizes a bracketed IPv6 bind address and checks that
+// --dual-stack, which listens on the wildcard address of each family, is not
+// combined with a specific address.
+func validateBind(cfg *Config) error {
+	bind := strings.TrimSuffix(strings.TrimPrefix(cfg.BindAddr, "["), "]")
+	ip := net.ParseIP(bind)
+	if ip == nil && strings.Contains(bind, ":") {
+		return fmt.Errorf("invalid bind address %q", cfg.BindAddr)
+	}
+	cfg.BindAddr = bind
+
+	if cfg.DualStack && (ip == nil || !ip.IsUnspecified()) {
+		return fmt.Errorf("--dual-stack listens on all addresses and cannot be combined with bind address %q", cfg.BindAddr)
+	}
+	return nil
+}
+
+// stringList is a flag.Value for repeatable flags. Each occurrence may also
+// carry several comma-separated values.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, splitList(value)...)
+	return nil
+}
+
+// cardList is a flag.Value for --expose-card. Unlike stringList it never
+// splits on commas, since ALSA long card names contain them.
+type cardList []string
+
+func (l *cardList) String() string {
+	return strings.Join(*l, ";")
+}
+
+func (l *cardList) Set(value string) error {
+	if value = strings.TrimSpace(value); value != "" {
+		*l = append(*l, value)
+	}
+	return nil
+}
+
+// splitCardList splits a list of cards separated by semicolons or newlines,
+// dropping empty entries.
+func splitCardList(value string) []string {
+	var cards []string
+	for _, v := range strings.FieldsFunc(value, func(r rune) bool { return r == ';' || r == '\n' }) {
+		if v = strings.TrimSpace(v); v != "" {
+			cards = append(cards, v)
+		}
+	}
+	return cards
+}
+
+// splitList splits a comma-separated list, dropping empty entries.
+func splitList(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
 }
 
 func Load() (*Config, error) {
 
-	cfg := &Config{Port: 8080, BindAddr: "0.0.0.0", CardIndex: 0, LogLevel: "info", MonitorFile: "/etc/asound.conf"}
+	cfg := &Config{Port: 8080, BindAddr: "0.0.0.0", CardIndex: 0, LogLevel: "info", MonitorFile: "/etc/asound.conf", PollInterval: 100 * time.Millisecond}
+	cfg.Shortcuts = make(map[string][]string, len(DefaultShortcuts))
+	for action, keys := range DefaultShortcuts {
+		cfg.Shortcuts[action] = keys
+	}
 
 	if v := os.Getenv("ALSAMIXER_WEB_PORT"); v != "" {
 		if p, err := strconv.Atoi(v); err == nil {
@@ -30,9 +238,17 @@ func Load() (*Config, error) {
 	if v := os.Getenv("ALSAMIXER_WEB_BIND"); v != "" {
 		cfg.BindAddr = v
 	}
+	if v := os.Getenv("ALSAMIXER_WEB_DUAL_STACK"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			cfg.DualStack = b
+		} else {
+			return nil, fmt.Errorf("invalid ALSAMIXER_WEB_DUAL_STACK: %q", v)
+		}
+	}
 	if v := os.Getenv("ALSAMIXER_WEB_CARD"); v != "" {
 		if c, err := strconv.ParseUint(v, 10, 64); err == nil {
 			cfg.CardIndex = uint(c)
+			cfg.CardSet = true
 		} else {
 			return nil, fmt.Errorf("invalid ALSAMIXER_WEB_CARD: %q", v)
 		}
@@ -43,21 +259,163 @@ func Load() (*Config, error) {
 	if v := os.Getenv("ALSAMIXER_WEB_MONITOR_FILE"); v != "" {
 		cfg.MonitorFile = v
 	}
+	if v := os.Getenv("ALSAMIXER_WEB_READ_ONLY"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			cfg.ReadOnly = b
+		} else {
+			return nil, fmt.Errorf("invalid ALSAMIXER_WEB_READ_ONLY: %q", v)
+		}
+	}
+	if v := os.Getenv("ALSAMIXER_WEB_DEBUG_LOGS"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			cfg.DebugLogs = b
+		} else {
+			return nil, fmt.Errorf("invalid ALSAMIXER_WEB_DEBUG_LOGS: %q", v)
+		}
+	}
+	if v := os.Getenv("ALSAMIXER_WEB_DRY_RUN"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			cfg.DryRun = b
+		} else {
+			return nil, fmt.Errorf("invalid ALSAMIXER_WEB_DRY_RUN: %q", v)
+		}
+	}
+	if v := os.Getenv("ALSAMIXER_WEB_STRICT_VOLUME"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			cfg.StrictVolume = b
+		} else {
+			return nil, fmt.Errorf("invalid ALSAMIXER_WEB_STRICT_VOLUME: %q", v)
+		}
+	}
+	if v := os.Getenv("ALSAMIXER_WEB_VOLUME_THRESHOLD"); v != "" {
+		if t, err := strconv.Atoi(v); err == nil && t >= 0 {
+			cfg.VolumeThreshold = t
+		} else {
+			return nil, fmt.Errorf("invalid ALSAMIXER_WEB_VOLUME_THRESHOLD: %q", v)
+		}
+	}
+	if v := os.Getenv("ALSAMIXER_WEB_POLL_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			cfg.PollInterval = d
+		} else {
+			return nil, fmt.Errorf("invalid ALSAMIXER_WEB_POLL_INTERVAL: %q", v)
+		}
+	}
+	if v := os.Getenv("ALSAMIXER_WEB_IDLE_POLL_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			cfg.IdlePollInterval = d
+		} else {
+			return nil, fmt.Errorf("invalid ALSAMIXER_WEB_IDLE_POLL_INTERVAL: %q", v)
+		}
+	}
+	if v := os.Getenv("ALSAMIXER_WEB_HANDLE_IDLE_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			cfg.HandleIdleTimeout = d
+		} else {
+			return nil, fmt.Errorf("invalid ALSAMIXER_WEB_HANDLE_IDLE_TIMEOUT: %q", v)
+		}
+	}
+	if v := os.Getenv("ALSAMIXER_WEB_DEDUPE_WINDOW"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			cfg.DedupeWindow = d
+		} else {
+			return nil, fmt.Errorf("invalid ALSAMIXER_WEB_DEDUPE_WINDOW: %q", v)
+		}
+	}
+	if v := os.Getenv("ALSAMIXER_WEB_ADMIN_TOKEN"); v != "" {
+		cfg.AdminToken = v
+	}
+	if v := os.Getenv("ALSAMIXER_WEB_ALLOW_GET_ACTIONS"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			cfg.AllowGetActions = b
+		} else {
+			return nil, fmt.Errorf("invalid ALSAMIXER_WEB_ALLOW_GET_ACTIONS: %q", v)
+		}
+	}
+	if v := os.Getenv("ALSAMIXER_WEB_ACTION_TOKEN"); v != "" {
+		cfg.ActionToken = v
+	}
+	if v := os.Getenv("ALSAMIXER_WEB_SHORTCUTS"); v != "" {
+		for _, binding := range splitList(v) {
+			action, keys, err := parseShortcut(binding)
+			if err != nil {
+				return nil, fmt.Errorf("invalid ALSAMIXER_WEB_SHORTCUTS: %w", err)
+			}
+			cfg.Shortcuts[action] = keys
+		}
+	}
+	if v := os.Getenv("ALSAMIXER_WEB_CARD_TRIM"); v != "" {
+		cfg.CardTrims = make(map[uint]int)
+		for _, trim := range splitList(v) {
+			card, offset, err := parseCardTrim(trim)
+			if err != nil {
+				return nil, fmt.Errorf("invalid ALSAMIXER_WEB_CARD_TRIM: %w", err)
+			}
+			cfg.CardTrims[card] = offset
+		}
+	}
+	if v := os.Getenv("ALSAMIXER_WEB_GROUP"); v != "" {
+		cfg.Groups = make(map[string][]GroupMember)
+		for _, group := range splitList(v) {
+			name, members, err := parseGroup(group)
+			if err != nil {
+				return nil, fmt.Errorf("invalid ALSAMIXER_WEB_GROUP: %w", err)
+			}
+			cfg.Groups[name] = members
+		}
+	}
+	if v := os.Getenv("ALSAMIXER_WEB_EXPOSE_CARD"); v != "" {
+		cfg.ExposeCards = splitCardList(v)
+	}
 
 	fs := flag.NewFlagSet("alsamixer-web", flag.ContinueOnError)
 	var portFlag int
 	var bindFlag string
+	var dualStackFlag bool
 	var cardFlag uint
 	var logLevelFlag string
 	var monitorFileFlag string
+	var readOnlyFlag bool
+	var exposeCardFlag cardList
+	var debugLogsFlag bool
+	var dryRunFlag bool
+	var strictVolumeFlag bool
+	var shortcutFlag stringList
+	var volumeThresholdFlag int
+	var pollIntervalFlag time.Duration
+	var idlePollIntervalFlag time.Duration
+	var handleIdleTimeoutFlag time.Duration
+	var dedupeWindowFlag time.Duration
+	var adminTokenFlag string
+	var allowGetActionsFlag bool
+	var actionTokenFlag string
+	var cardTrimFlag stringList
+	var groupFlag stringList
 	fs.IntVar(&portFlag, "port", cfg.Port, "Server port")
 	fs.IntVar(&portFlag, "p", cfg.Port, "Server port (shorthand)")
 	fs.StringVar(&bindFlag, "bind", cfg.BindAddr, "Bind address")
 	fs.StringVar(&bindFlag, "b", cfg.BindAddr, "Bind address (shorthand)")
+	fs.BoolVar(&dualStackFlag, "dual-stack", cfg.DualStack, "Listen on all IPv4 and IPv6 addresses")
 	fs.UintVar(&cardFlag, "card", cfg.CardIndex, "ALSA card index")
 	fs.UintVar(&cardFlag, "c", cfg.CardIndex, "ALSA card index (shorthand)")
 	fs.StringVar(&logLevelFlag, "log-level", cfg.LogLevel, "Log level")
 	fs.StringVar(&monitorFileFlag, "monitor-file", cfg.MonitorFile, "Path to ALSA config file to monitor")
+	fs.BoolVar(&readOnlyFlag, "read-only", cfg.ReadOnly, "Display only; reject all control changes")
+	fs.Var(&exposeCardFlag, "expose-card", "Only expose this card (index or name); repeatable")
+	fs.BoolVar(&debugLogsFlag, "debug-logs", cfg.DebugLogs, "Stream the application log at /debug/logs (may expose sensitive data)")
+	fs.BoolVar(&dryRunFlag, "dry-run", cfg.DryRun, "Log and broadcast control changes without applying them")
+	fs.BoolVar(&strictVolumeFlag, "strict-volume", cfg.StrictVolume, "Reject volumes outside 0-100 with 400 instead of clamping them")
+	fs.Var(&shortcutFlag, "shortcut", "Bind keys to an action, e.g. \"mute=m\" (volume-up, volume-down, mute, capture); repeatable")
+	fs.IntVar(&volumeThresholdFlag, "volume-threshold", cfg.VolumeThreshold, "Ignore monitored volume changes smaller than this many percent")
+	fs.DurationVar(&pollIntervalFlag, "poll-interval", cfg.PollInterval, "How often to read the mixer while clients are active")
+	fs.DurationVar(&idlePollIntervalFlag, "idle-poll-interval", cfg.IdlePollInterval, "Slower interval to read the mixer at when clients are idle (0 disables)")
+	fs.DurationVar(&handleIdleTimeoutFlag, "handle-idle-timeout", cfg.HandleIdleTimeout, "Close a card's mixer handle after it is unused for this long (0 reopens it on every read)")
+	fs.DurationVar(&dedupeWindowFlag, "dedupe-window", cfg.DedupeWindow, "Drop a control change broadcast repeating the previous one within this long (0 disables)")
+	fs.StringVar(&adminTokenFlag, "admin-token", cfg.AdminToken, "Bearer token enabling the /admin endpoints (prefer ALSAMIXER_WEB_ADMIN_TOKEN)")
+	fs.BoolVar(&allowGetActionsFlag, "allow-get-actions", cfg.AllowGetActions, "Allow changing controls with GET /action/... links that carry the action token")
+	fs.StringVar(&actionTokenFlag, "action-token", cfg.ActionToken, "Token GET actions must carry; generated at startup if empty (prefer ALSAMIXER_WEB_ACTION_TOKEN)")
+	fs.Var(&cardTrimFlag, "card-trim", "Offset a card's volumes by percentage points, e.g. \"1=+10\"; repeatable")
+	fs.Var(&groupFlag, "group", "Set several controls with one volume, e.g. \"speakers=0:Front+0:Surround*0.8\"; repeatable")
 	var helpFlag bool
 	fs.BoolVar(&helpFlag, "help", false, "Show help")
 	if err := fs.Parse(os.Args[1:]); err != nil {
@@ -67,13 +425,79 @@ func Load() (*Config, error) {
 	}
 	cfg.Port = portFlag
 	cfg.BindAddr = bindFlag
+	cfg.DualStack = dualStackFlag
 	cfg.CardIndex = cardFlag
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "card" || f.Name == "c" {
+			cfg.CardSet = true
+		}
+	})
+	cfg.ReadOnly = readOnlyFlag
+	cfg.DebugLogs = debugLogsFlag
+	cfg.DryRun = dryRunFlag
+	cfg.StrictVolume = strictVolumeFlag
+	if volumeThresholdFlag < 0 {
+		return nil, fmt.Errorf("invalid --volume-threshold: %d", volumeThresholdFlag)
+	}
+	cfg.VolumeThreshold = volumeThresholdFlag
+	if pollIntervalFlag <= 0 {
+		return nil, fmt.Errorf("invalid --poll-interval: %v", pollIntervalFlag)
+	}
+	if idlePollIntervalFlag < 0 {
+		return nil, fmt.Errorf("invalid --idle-poll-interval: %v", idlePollIntervalFlag)
+	}
+	cfg.PollInterval = pollIntervalFlag
+	cfg.IdlePollInterval = idlePollIntervalFlag
+	if handleIdleTimeoutFlag < 0 {
+		return nil, fmt.Errorf("invalid --handle-idle-timeout: %v", handleIdleTimeoutFlag)
+	}
+	cfg.HandleIdleTimeout = handleIdleTimeoutFlag
+	if dedupeWindowFlag < 0 {
+		return nil, fmt.Errorf("invalid --dedupe-window: %v", dedupeWindowFlag)
+	}
+	cfg.DedupeWindow = dedupeWindowFlag
+	cfg.AdminToken = adminTokenFlag
+	cfg.AllowGetActions = allowGetActionsFlag
+	cfg.ActionToken = actionTokenFlag
+	for _, binding := range shortcutFlag {
+		action, keys, err := parseShortcut(binding)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Shortcuts[action] = keys
+	}
+	if len(cardTrimFlag) > 0 {
+		cfg.CardTrims = make(map[uint]int)
+		for _, trim := range cardTrimFlag {
+			card, offset, err := parseCardTrim(trim)
+			if err != nil {
+				return nil, err
+			}
+			cfg.CardTrims[card] = offset
+		}
+	}
+	if len(groupFlag) > 0 {
+		cfg.Groups = make(map[string][]GroupMember)
+		for _, group := range groupFlag {
+			name, members, err := parseGroup(group)
+			if err != nil {
+				return nil, err
+			}
+			cfg.Groups[name] = members
+		}
+	}
+	if len(exposeCardFlag) > 0 {
+		cfg.ExposeCards = exposeCardFlag
+	}
 	if logLevelFlag != "" {
 		cfg.LogLevel = logLevelFlag
 	}
 	if monitorFileFlag != "" {
 		cfg.MonitorFile = monitorFileFlag
 	}
+	if err := validateBind(cfg); err != nil {
+		return nil, err
+	}
 	return cfg, nil
 }
 
@@ -84,10 +508,27 @@ func HelpText() string {
 	fs.Int("p", 8080, "Server port (shorthand)")
 	fs.String("bind", "0.0.0.0", "Bind address")
 	fs.String("b", "0.0.0.0", "Bind address (shorthand)")
+	fs.Bool("dual-stack", false, "Listen on all IPv4 and IPv6 addresses")
 	fs.Uint("card", 0, "ALSA card index")
 	fs.Uint("c", 0, "ALSA card index (shorthand)")
 	fs.String("log-level", "info", "Log level")
 	fs.String("monitor-file", "/etc/asound.conf", "Path to ALSA config file to monitor")
+	fs.Bool("read-only", false, "Display only; reject all control changes")
+	fs.Var(new(cardList), "expose-card", "Only expose this card (index or name); repeatable")
+	fs.Bool("debug-logs", false, "Stream the application log at /debug/logs (may expose sensitive data)")
+	fs.Bool("dry-run", false, "Log and broadcast control changes without applying them")
+	fs.Bool("strict-volume", false, "Reject volumes outside 0-100 with 400 instead of clamping them")
+	fs.Var(new(stringList), "shortcut", "Bind keys to an action, e.g. \"mute=m\" (volume-up, volume-down, mute, capture); repeatable")
+	fs.Int("volume-threshold", 0, "Ignore monitored volume changes smaller than this many percent")
+	fs.Duration("poll-interval", 100*time.Millisecond, "How often to read the mixer while clients are active")
+	fs.Duration("idle-poll-interval", 0, "Slower interval to read the mixer at when clients are idle (0 disables)")
+	fs.Duration("handle-idle-timeout", 0, "Close a card's mixer handle after it is unused for this long (0 reopens it on every read)")
+	fs.Duration("dedupe-window", 0, "Drop a control change broadcast repeating the previous one within this long (0 disables)")
+	fs.String("admin-token", "", "Bearer token enabling the /admin endpoints (prefer ALSAMIXER_WEB_ADMIN_TOKEN)")
+	fs.Bool("allow-get-actions", false, "Allow changing controls with GET /action/... links that carry the action token")
+	fs.String("action-token", "", "Token GET actions must carry; generated at startup if empty (prefer ALSAMIXER_WEB_ACTION_TOKEN)")
+	fs.Var(new(stringList), "card-trim", "Offset a card's volumes by percentage points, e.g. \"1=+10\"; repeatable")
+	fs.Var(new(stringList), "group", "Set several controls with one volume, e.g. \"speakers=0:Front+0:Surround*0.8\"; repeatable")
 	fs.SetOutput(&buf)
 	fs.Usage()
 	return buf.String()
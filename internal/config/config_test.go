@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"reflect"
 	"testing"
 )
 
@@ -29,7 +30,7 @@ func TestLoadDefaults(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Load() returned error: %v", err)
 	}
-	if cfg.Port != 8080 || cfg.BindAddr != "0.0.0.0" || cfg.CardIndex != 0 || cfg.LogLevel != "info" {
+	if cfg.Port != 8080 || cfg.BindAddr != "0.0.0.0" || cfg.CardIndex != 0 || cfg.CardSet || cfg.LogLevel != "info" {
 		t.Fatalf("unexpected defaults: %+v", cfg)
 	}
 }
@@ -54,7 +55,7 @@ func TestLoadEnvOverrides(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Load() returned error: %v", err)
 	}
-	if cfg.Port != 1234 || cfg.BindAddr != "127.0.0.1" || cfg.CardIndex != 2 || cfg.LogLevel != "debug" {
+	if cfg.Port != 1234 || cfg.BindAddr != "127.0.0.1" || cfg.CardIndex != 2 || !cfg.CardSet || cfg.LogLevel != "debug" {
 		t.Fatalf("env override failed: %+v", cfg)
 	}
 }
@@ -79,11 +80,222 @@ func TestLoadCLIOverrides(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Load() returned error: %v", err)
 	}
-	if cfg.Port != 9090 || cfg.BindAddr != "127.0.0.2" || cfg.CardIndex != 4 || cfg.LogLevel != "error" {
+	if cfg.Port != 9090 || cfg.BindAddr != "127.0.0.2" || cfg.CardIndex != 4 || !cfg.CardSet || cfg.LogLevel != "error" {
 		t.Fatalf("CLI override failed: %+v", cfg)
 	}
 }
 
+func TestLoadReadOnly(t *testing.T) {
+	origArgs := os.Args
+	defer func() {
+		os.Args = origArgs
+		os.Unsetenv("ALSAMIXER_WEB_READ_ONLY")
+	}()
+
+	os.Args = []string{"cmd"}
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	if cfg.ReadOnly {
+		t.Fatal("expected read-only mode to be off by default")
+	}
+
+	os.Args = []string{"cmd", "--read-only"}
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	if !cfg.ReadOnly {
+		t.Fatal("expected --read-only to enable read-only mode")
+	}
+
+	os.Args = []string{"cmd"}
+	os.Setenv("ALSAMIXER_WEB_READ_ONLY", "maybe")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for invalid ALSAMIXER_WEB_READ_ONLY")
+	}
+}
+
+func TestLoadExposeCards(t *testing.T) {
+	origArgs := os.Args
+	defer func() {
+		os.Args = origArgs
+		os.Unsetenv("ALSAMIXER_WEB_EXPOSE_CARD")
+	}()
+
+	os.Args = []string{"cmd", "--expose-card", "0", "--expose-card", "USB"}
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	if len(cfg.ExposeCards) != 2 || cfg.ExposeCards[0] != "0" || cfg.ExposeCards[1] != "USB" {
+		t.Fatalf("expected repeated --expose-card to collect [0 USB], got %v", cfg.ExposeCards)
+	}
+
+	os.Args = []string{"cmd"}
+	os.Setenv("ALSAMIXER_WEB_EXPOSE_CARD", "PCH; 2")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	if len(cfg.ExposeCards) != 2 || cfg.ExposeCards[0] != "PCH" || cfg.ExposeCards[1] != "2" {
+		t.Fatalf("expected ALSAMIXER_WEB_EXPOSE_CARD to give [PCH 2], got %v", cfg.ExposeCards)
+	}
+}
+
+func TestLoadExposeCardsLongNames(t *testing.T) {
+	origArgs := os.Args
+	defer func() {
+		os.Args = origArgs
+		os.Unsetenv("ALSAMIXER_WEB_EXPOSE_CARD")
+	}()
+
+	// Long names of identical USB dongles differ only by port, after a comma.
+	first := "USB Audio at usb-0000:00:14.0-2, high speed"
+	second := "USB Audio at usb-0000:00:14.0-3, high speed"
+
+	os.Args = []string{"cmd", "--expose-card", first, "--expose-card", second}
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	if !reflect.DeepEqual(cfg.ExposeCards, []string{first, second}) {
+		t.Fatalf("expected --expose-card to keep long names whole, got %q", cfg.ExposeCards)
+	}
+
+	os.Args = []string{"cmd"}
+	os.Setenv("ALSAMIXER_WEB_EXPOSE_CARD", first+"\n"+second)
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	if !reflect.DeepEqual(cfg.ExposeCards, []string{first, second}) {
+		t.Fatalf("expected ALSAMIXER_WEB_EXPOSE_CARD to keep long names whole, got %q", cfg.ExposeCards)
+	}
+}
+
+func TestLoadShortcuts(t *testing.T) {
+	origArgs := os.Args
+	defer func() {
+		os.Args = origArgs
+		os.Unsetenv("ALSAMIXER_WEB_SHORTCUTS")
+	}()
+
+	os.Args = []string{"cmd"}
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	if got := cfg.Shortcuts["mute"]; len(got) != 1 || got[0] != "m" {
+		t.Fatalf("expected default mute shortcut [m], got %v", got)
+	}
+
+	os.Setenv("ALSAMIXER_WEB_SHORTCUTS", "mute=x")
+	os.Args = []string{"cmd", "--shortcut", "volume-up=k ArrowUp"}
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	if got := cfg.Shortcuts["mute"]; len(got) != 1 || got[0] != "x" {
+		t.Fatalf("expected ALSAMIXER_WEB_SHORTCUTS to rebind mute to [x], got %v", got)
+	}
+	if got := cfg.Shortcuts["volume-up"]; len(got) != 2 || got[0] != "k" || got[1] != "ArrowUp" {
+		t.Fatalf("expected --shortcut to rebind volume-up to [k ArrowUp], got %v", got)
+	}
+	if got := cfg.Shortcuts["capture"]; len(got) != 1 || got[0] != "c" {
+		t.Fatalf("expected capture to keep its default, got %v", got)
+	}
+	if DefaultShortcuts["mute"][0] != "m" {
+		t.Fatalf("overrides must not modify DefaultShortcuts")
+	}
+
+	os.Unsetenv("ALSAMIXER_WEB_SHORTCUTS")
+	os.Args = []string{"cmd", "--shortcut", "solo=s"}
+	if _, err := Load(); err == nil {
+		t.Fatal("expected an error for an unknown shortcut action")
+	}
+}
+
+func TestLoadCardTrims(t *testing.T) {
+	origArgs := os.Args
+	defer func() { os.Args = origArgs }()
+
+	os.Args = []string{"cmd", "--card-trim", "1=+10,2=-5"}
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	if cfg.CardTrims[1] != 10 || cfg.CardTrims[2] != -5 || len(cfg.CardTrims) != 2 {
+		t.Fatalf("expected trims map[1:10 2:-5], got %v", cfg.CardTrims)
+	}
+
+	for _, bad := range []string{"10", "usb=5", "1=loud", "1=150"} {
+		os.Args = []string{"cmd", "--card-trim", bad}
+		if _, err := Load(); err == nil {
+			t.Errorf("expected an error for --card-trim %q", bad)
+		}
+	}
+}
+
+func TestLoadGroups(t *testing.T) {
+	origArgs := os.Args
+	defer func() { os.Args = origArgs }()
+
+	os.Args = []string{"cmd", "--group", "speakers=0:Front+0:Surround Playback Volume*0.8", "--group", "mics=1:Mic"}
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	want := []GroupMember{{Card: 0, Control: "Front", Scale: 1}, {Card: 0, Control: "Surround Playback Volume", Scale: 0.8}}
+	if !reflect.DeepEqual(cfg.Groups["speakers"], want) {
+		t.Errorf("expected speakers %+v, got %+v", want, cfg.Groups["speakers"])
+	}
+	if len(cfg.Groups) != 2 || len(cfg.Groups["mics"]) != 1 {
+		t.Errorf("expected two groups, got %+v", cfg.Groups)
+	}
+
+	for _, bad := range []string{"speakers", "my speakers=0:Front", "speakers=Front", "speakers=0:Front*0", "speakers=0:Front*NaN", "speakers=0:Front*Inf", "speakers=0:Front*loud", "speakers=0:Front+"} {
+		os.Args = []string{"cmd", "--group", bad}
+		if _, err := Load(); err == nil {
+			t.Errorf("expected an error for --group %q", bad)
+		}
+	}
+}
+
+func TestLoadBindAddressFamily(t *testing.T) {
+	origArgs := os.Args
+	defer func() { os.Args = origArgs }()
+
+	os.Args = []string{"cmd", "--bind", "[::1]"}
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	if cfg.BindAddr != "::1" {
+		t.Fatalf("expected bracketed IPv6 address to be normalized to ::1, got %q", cfg.BindAddr)
+	}
+
+	os.Args = []string{"cmd", "--bind", "::", "--dual-stack"}
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	if !cfg.DualStack {
+		t.Fatal("expected --dual-stack to be set")
+	}
+
+	for _, args := range [][]string{
+		{"cmd", "--bind", "fe80::1::2"},
+		{"cmd", "--bind", "127.0.0.1", "--dual-stack"},
+	} {
+		os.Args = args
+		if _, err := Load(); err == nil {
+			t.Errorf("expected %v to be rejected", args[1:])
+		}
+	}
+}
+
 func TestHelpTextIncludesFlags(t *testing.T) {
 	text := HelpText()
 	if !(contains(text, "-port") || contains(text, "--port")) {
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/user/alsamixer-web/internal/alsa"
+)
+
+// newActionToken returns a random token for GET actions, used when
+// --action-token is not set.
+func newActionToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate action token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// MuteActionHandler serves GET /action/mute?card=0&control=Master&token=...,
+// which toggles a control's mute switch and redirects to the mixer, for
+// bookmarklets and clients that cannot send a POST. Since a GET that
+// changes state can be triggered by any page that links to it, it needs
+// --allow-get-actions and the action token.
+func (s *Server) MuteActionHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.config.AllowGetActions {
+		http.Error(w, "GET actions are disabled; start the server with --allow-get-actions", http.StatusMethodNotAllowed)
+		return
+	}
+	query := r.URL.Query()
+	if subtle.ConstantTimeCompare([]byte(query.Get("token")), []byte(s.actionToken)) != 1 {
+		http.Error(w, "missing or invalid action token", http.StatusForbidden)
+		return
+	}
+
+	cardStr := query.Get("card")
+	control := query.Get("control")
+	if cardStr == "" || control == "" {
+		http.Error(w, "missing card or control", http.StatusBadRequest)
+		return
+	}
+	cardValue, err := strconv.ParseUint(cardStr, 10, 0)
+	if err != nil {
+		http.Error(w, "invalid card", http.StatusBadRequest)
+		return
+	}
+	cardID := uint(cardValue)
+
+	m := s.mixer
+	control = s.resolveVolumeControlName(cardID, control)
+	switchControl := strings.Replace(control, " Volume", " Switch", 1)
+	if !checkSupports(w, m, cardID, switchControl, alsa.OpMute) {
+		return
+	}
+	muted, err := m.GetMute(cardID, switchControl)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to get mute state: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if err := m.SetMute(cardID, switchControl, !muted); err != nil {
+		http.Error(w, fmt.Sprintf("failed to set mute state: %v", err), http.StatusInternalServerError)
+		return
+	}
+	log.Printf("[GET /action/mute] card=%d control=%s muted=%t", cardID, control, !muted)
+
+	if ctrl := s.getControlView(cardID, control); ctrl != nil {
+		s.broadcastControl(cardID, control, ctrl.VolumeNow, !muted)
+	}
+
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
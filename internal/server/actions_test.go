@@ -0,0 +1,118 @@
+package server
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/user/alsamixer-web/internal/config"
+	"github.com/user/alsamixer-web/internal/sse"
+)
+
+// switchMixer is a fakeMixer that remembers its switches' states.
+type switchMixer struct {
+	*fakeMixer
+	mu    sync.Mutex
+	muted map[string]bool
+}
+
+func (m *switchMixer) GetMute(card uint, control string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.muted[control], nil
+}
+
+func (m *switchMixer) SetMute(card uint, control string, muted bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.muted[control] = muted
+	return nil
+}
+
+func TestMuteActionHandler(t *testing.T) {
+	get := func(srv *Server, target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		resp := httptest.NewRecorder()
+		srv.mux.ServeHTTP(resp, req)
+		return resp
+	}
+	const target = "/action/mute?card=0&control=Master&token=s3cret"
+
+	t.Run("enabled", func(t *testing.T) {
+		cfg := &config.Config{
+			Port:            0,
+			BindAddr:        "127.0.0.1",
+			AllowGetActions: true,
+			ActionToken:     "s3cret",
+		}
+		srv := newTestServer(t, cfg, sse.NewHub())
+		sm := &switchMixer{fakeMixer: &fakeMixer{}, muted: map[string]bool{}}
+		srv.useMixer(sm)
+
+		for _, want := range []bool{true, false} {
+			resp := get(srv, target)
+			if resp.Code != http.StatusSeeOther {
+				t.Fatalf("expected status %d, got %d: %s", http.StatusSeeOther, resp.Code, resp.Body.String())
+			}
+			if loc := resp.Header().Get("Location"); loc != "/" {
+				t.Errorf("expected a redirect to /, got %q", loc)
+			}
+			if got := sm.muted["Master Playback Switch"]; got != want {
+				t.Errorf("expected Master muted=%t, got %t", want, got)
+			}
+		}
+
+		resp := get(srv, "/action/mute?card=0&control=Master&token=guess")
+		if resp.Code != http.StatusForbidden {
+			t.Errorf("expected status %d for a wrong token, got %d", http.StatusForbidden, resp.Code)
+		}
+		if sm.muted["Master Playback Switch"] {
+			t.Error("expected a request with a wrong token not to toggle mute")
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		cfg := &config.Config{
+			Port:        0,
+			BindAddr:    "127.0.0.1",
+			ActionToken: "s3cret",
+		}
+		srv := newTestServer(t, cfg, sse.NewHub())
+		sm := &switchMixer{fakeMixer: &fakeMixer{}, muted: map[string]bool{}}
+		srv.useMixer(sm)
+
+		resp := get(srv, target)
+		if resp.Code != http.StatusMethodNotAllowed {
+			t.Fatalf("expected status %d, got %d", http.StatusMethodNotAllowed, resp.Code)
+		}
+		if sm.muted["Master Playback Switch"] {
+			t.Error("expected mute not to be toggled with GET actions disabled")
+		}
+	})
+}
+
+func TestGeneratedActionTokenIsNotLogged(t *testing.T) {
+	var logs bytes.Buffer
+	origOutput := log.Writer()
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(origOutput) })
+
+	cfg := &config.Config{
+		Port:            0,
+		BindAddr:        "127.0.0.1",
+		AllowGetActions: true,
+		DebugLogs:       true,
+	}
+	srv := newTestServer(t, cfg, sse.NewHub())
+
+	if srv.actionToken == "" {
+		t.Fatal("expected a generated action token")
+	}
+	if strings.Contains(logs.String(), srv.actionToken) {
+		t.Errorf("expected the generated token to stay out of the log, got:\n%s", logs.String())
+	}
+}
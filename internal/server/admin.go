@@ -0,0 +1,72 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/user/alsamixer-web/internal/sse"
+)
+
+// maxBroadcastSize bounds the body accepted by POST /admin/broadcast.
+const maxBroadcastSize = 64 << 10
+
+// eventTypePattern keeps custom event types to names that are safe on an
+// SSE "event:" line and easy to listen for in the browser.
+var eventTypePattern = regexp.MustCompile(`^[a-z][a-z0-9-]{0,63}$`)
+
+// reservedEventTypes are broadcast by the server itself. Injecting them
+// would let an admin request spoof mixer state in every client.
+var reservedEventTypes = map[string]bool{
+	"mixer-update":     true,
+	"controls-changed": true,
+	"card-list-change": true,
+	"config-change":    true,
+}
+
+// customEvent is the body of POST /admin/broadcast.
+type customEvent struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// requireAdmin rejects requests that do not carry the configured admin
+// token as a bearer token.
+func (s *Server) requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.config.AdminToken)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="alsamixer-web"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// AdminBroadcastHandler serves POST /admin/broadcast. It sends a custom
+// {type, data} event verbatim to every SSE client, for exercising the
+// event pipeline and for pushing notices from other systems.
+func (s *Server) AdminBroadcastHandler(w http.ResponseWriter, r *http.Request) {
+	var ev customEvent
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBroadcastSize)).Decode(&ev); err != nil {
+		http.Error(w, fmt.Sprintf("invalid event: %v", err), http.StatusBadRequest)
+		return
+	}
+	if !eventTypePattern.MatchString(ev.Type) {
+		http.Error(w, fmt.Sprintf("invalid event type %q: expected lowercase letters, digits and dashes", ev.Type), http.StatusBadRequest)
+		return
+	}
+	if reservedEventTypes[ev.Type] {
+		http.Error(w, fmt.Sprintf("event type %q is reserved", ev.Type), http.StatusBadRequest)
+		return
+	}
+
+	log.Printf("[POST /admin/broadcast] broadcasting custom event type=%s", ev.Type)
+	s.hub.Broadcast(sse.Event{Type: ev.Type, Data: ev.Data})
+	w.WriteHeader(http.StatusAccepted)
+}
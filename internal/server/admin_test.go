@@ -0,0 +1,141 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/alsamixer-web/internal/config"
+	"github.com/user/alsamixer-web/internal/sse"
+)
+
+func postBroadcast(t *testing.T, baseURL, token, body string) int {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodPost, baseURL+"/admin/broadcast", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to post broadcast: %v", err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestAdminBroadcast(t *testing.T) {
+	cfg := &config.Config{
+		Port:       0,
+		BindAddr:   "127.0.0.1",
+		AdminToken: "secret",
+	}
+	hub := sse.NewHub()
+	srv := newTestServer(t, cfg, hub)
+	go hub.Run()
+
+	ts := httptest.NewServer(srv.mux)
+	t.Cleanup(ts.Close)
+	lines := subscribeEvents(t, ts.URL, hub)
+
+	body := `{"type":"notice","data":{"text":"maintenance in 5 min"}}`
+	if code := postBroadcast(t, ts.URL, "secret", body); code != http.StatusAccepted {
+		t.Fatalf("expected status %d, got %d", http.StatusAccepted, code)
+	}
+
+	data := waitForEvent(t, lines, "notice", 2*time.Second)
+	if data != `{"text":"maintenance in 5 min"}` {
+		t.Errorf("expected data to be broadcast verbatim, got %s", data)
+	}
+}
+
+func TestAdminBroadcastRejects(t *testing.T) {
+	cfg := &config.Config{
+		Port:       0,
+		BindAddr:   "127.0.0.1",
+		AdminToken: "secret",
+	}
+	srv := newTestServer(t, cfg, sse.NewHub())
+	ts := httptest.NewServer(srv.mux)
+	t.Cleanup(ts.Close)
+
+	tests := []struct {
+		name  string
+		token string
+		body  string
+		want  int
+	}{
+		{"missing token", "", `{"type":"notice"}`, http.StatusUnauthorized},
+		{"wrong token", "guess", `{"type":"notice"}`, http.StatusUnauthorized},
+		{"empty type", "secret", `{"data":1}`, http.StatusBadRequest},
+		{"type with newline", "secret", `{"type":"notice\ndata: x"}`, http.StatusBadRequest},
+		{"reserved type", "secret", `{"type":"mixer-update","data":{}}`, http.StatusBadRequest},
+		{"malformed body", "secret", `{"type":`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := postBroadcast(t, ts.URL, tt.token, tt.body); code != tt.want {
+				t.Errorf("expected status %d, got %d", tt.want, code)
+			}
+		})
+	}
+}
+
+func TestAdminBroadcastDisabledWithoutToken(t *testing.T) {
+	cfg := &config.Config{
+		Port:     0,
+		BindAddr: "127.0.0.1",
+	}
+	srv := newTestServer(t, cfg, sse.NewHub())
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/broadcast", strings.NewReader(`{"type":"notice"}`))
+	resp := httptest.NewRecorder()
+	srv.mux.ServeHTTP(resp, req)
+	if resp.Code != http.StatusNotFound {
+		t.Errorf("expected status %d without an admin token, got %d", http.StatusNotFound, resp.Code)
+	}
+}
+
+func TestDebugConfigRedactsSecrets(t *testing.T) {
+	cfg := &config.Config{
+		Port:       8123,
+		BindAddr:   "127.0.0.1",
+		AdminToken: "secret",
+	}
+	srv := newTestServer(t, cfg, sse.NewHub())
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/config", nil)
+	resp := httptest.NewRecorder()
+	srv.mux.ServeHTTP(resp, req)
+	if resp.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status %d without the admin token, got %d", http.StatusUnauthorized, resp.Code)
+	}
+
+	req.Header.Set("Authorization", "Bearer secret")
+	resp = httptest.NewRecorder()
+	srv.mux.ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.Code)
+	}
+
+	var got config.Config
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if got.Port != 8123 {
+		t.Errorf("expected port 8123, got %d", got.Port)
+	}
+	if got.AdminToken == "secret" || got.AdminToken == "" {
+		t.Errorf("expected the admin token to be redacted, got %q", got.AdminToken)
+	}
+	if cfg.AdminToken != "secret" {
+		t.Error("expected redaction not to modify the server's config")
+	}
+}
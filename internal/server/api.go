@@ -0,0 +1,430 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/user/alsamixer-web/internal/alsa"
+	"github.com/user/alsamixer-web/internal/sse"
+)
+
+// capabilitiesDoc is the JSON document served by GET /api/capabilities.
+type capabilitiesDoc struct {
+	Cards []cardCapabilities `json:"cards"`
+}
+
+type cardCapabilities struct {
+	ID       uint                  `json:"id"`
+	Name     string                `json:"name"`
+	Controls []controlCapabilities `json:"controls"`
+}
+
+type controlCapabilities struct {
+	Name           string `json:"name"`
+	Type           string `json:"type"`
+	Min            int64  `json:"min"`
+	Max            int64  `json:"max"`
+	Channels       int    `json:"channels"`
+	PlaybackVolume bool   `json:"playbackVolume"`
+	PlaybackSwitch bool   `json:"playbackSwitch"`
+	CaptureVolume  bool   `json:"captureVolume"`
+	CaptureSwitch  bool   `json:"captureSwitch"`
+}
+
+// capabilitiesCache holds the serialized capabilities document. Building it
+// shells out to amixer for every control, so it is only rebuilt on the first
+// request after the monitor reports a card-list or control-list change.
+type capabilitiesCache struct {
+	mu   sync.Mutex
+	body []byte
+	etag string
+}
+
+// get returns the cached document, building it with build if the cache is empty.
+func (c *capabilitiesCache) get(build func() ([]byte, error)) ([]byte, string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.body != nil {
+		return c.body, c.etag, nil
+	}
+
+	body, err := build()
+	if err != nil {
+		return nil, "", err
+	}
+
+	h := fnv.New64a()
+	h.Write(body)
+	c.body = body
+	c.etag = fmt.Sprintf("\"%016x\"", h.Sum64())
+	return c.body, c.etag, nil
+}
+
+// invalidate drops the cached document so the next request rebuilds it.
+func (c *capabilitiesCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.body = nil
+	c.etag = ""
+}
+
+// buildCapabilities enumerates every card and control and serializes their
+// static capabilities.
+func (s *Server) buildCapabilities() ([]byte, error) {
+	doc := capabilitiesDoc{Cards: []cardCapabilities{}}
+
+	if s.mixer != nil && s.mixer.IsOpen() {
+		cards, err := s.listCards()
+		if err != nil {
+			log.Printf("failed to list cards: %v", err)
+		}
+		for _, card := range cards {
+			cc := cardCapabilities{ID: card.ID, Name: card.Name, Controls: []controlCapabilities{}}
+
+			controls, err := s.mixer.ListControls(card.ID)
+			if err != nil {
+				log.Printf("failed to list controls for card %d: %v", card.ID, err)
+			}
+			for _, ctrl := range controls {
+				caps := controlCapabilities{
+					Name:     ctrl.Name,
+					Type:     ctrl.Type,
+					Min:      ctrl.Min,
+					Max:      ctrl.Max,
+					Channels: ctrl.Count,
+				}
+				caps.PlaybackVolume, _ = s.mixer.HasPlaybackVolume(card.ID, ctrl.Name)
+				caps.PlaybackSwitch, _ = s.mixer.HasPlaybackSwitch(card.ID, ctrl.Name)
+				caps.CaptureVolume, _ = s.mixer.HasCaptureVolume(card.ID, ctrl.Name)
+				caps.CaptureSwitch, _ = s.mixer.HasCaptureSwitch(card.ID, ctrl.Name)
+				cc.Controls = append(cc.Controls, caps)
+			}
+
+			doc.Cards = append(doc.Cards, cc)
+		}
+	}
+
+	return json.Marshal(doc)
+}
+
+// CapabilitiesHandler serves GET /api/capabilities from the in-memory cache,
+// honouring If-None-Match so clients can revalidate cheaply.
+func (s *Server) CapabilitiesHandler(w http.ResponseWriter, r *http.Request) {
+	body, etag, err := s.capabilities.get(s.buildCapabilities)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to build capabilities: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body)
+}
+
+// cardSummary is the JSON representation of a card in API responses.
+type cardSummary struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+// RescanHandler handles POST /api/rescan. It re-enumerates cards and
+// controls from scratch, drops cached capabilities and mixer handles, has
+// the monitor poll straight away and tells clients the card list may have
+// changed. This is the manual counterpart to automatic hotplug detection.
+func (s *Server) RescanHandler(w http.ResponseWriter, r *http.Request) {
+	if reason := s.mixerUnavailableReason(); reason != "" {
+		http.Error(w, "mixer not available: "+reason, http.StatusServiceUnavailable)
+		return
+	}
+
+	s.capabilities.invalidate()
+	if closer, ok := s.hw.(idleHandleCloser); ok {
+		closer.CloseIdleHandles()
+	}
+	if s.monitor != nil {
+		s.monitor.Rescan()
+	}
+
+	cards, err := s.listCards()
+	if err != nil {
+		log.Printf("rescan: failed to list cards: %v", err)
+		http.Error(w, "failed to list cards", http.StatusInternalServerError)
+		return
+	}
+
+	summaries := make([]cardSummary, 0, len(cards))
+	for _, card := range cards {
+		summaries = append(summaries, cardSummary{ID: card.ID, Name: card.Name})
+	}
+
+	log.Printf("[POST /api/rescan] found %d cards", len(summaries))
+
+	if s.hub != nil {
+		go s.hub.Broadcast(sse.Event{
+			Type: "card-list-change",
+			Data: map[string]interface{}{
+				"cards":     summaries,
+				"source":    "rescan",
+				"timestamp": time.Now().UnixMilli(),
+			},
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"cards": summaries,
+	})
+}
+
+// StateHandler serves GET /api/state with the monitor's latest reading of
+// every control and each card's primary control. With ?since=<unixmillis>
+// only controls that changed after that time are included, so clients
+// without SSE can poll for deltas. The response timestamp is meant to be
+// passed as the next request's since.
+func (s *Server) StateHandler(w http.ResponseWriter, r *http.Request) {
+	var since time.Time
+	if v := r.URL.Query().Get("since"); v != "" {
+		ms, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || ms < 0 {
+			http.Error(w, "invalid since", http.StatusBadRequest)
+			return
+		}
+		since = time.UnixMilli(ms)
+	}
+
+	if s.monitor == nil {
+		reason := s.mixerUnavailableReason()
+		if reason == "" {
+			reason = "monitor not running"
+		}
+		http.Error(w, "mixer not available: "+reason, http.StatusServiceUnavailable)
+		return
+	}
+
+	// Taken before reading so a change racing with this request is
+	// reported again next time rather than missed.
+	now := time.Now()
+	state := s.monitor.StateSince(since)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"state":     state,
+		"primary":   s.primaryControls(),
+		"timestamp": now.UnixMilli(),
+	})
+}
+
+// primaryControls returns the primary control of each exposed card that
+// has one, keyed by card ID.
+func (s *Server) primaryControls() map[uint]string {
+	primary := make(map[uint]string)
+	cards, err := s.listCards()
+	if err != nil {
+		return primary
+	}
+	for _, card := range cards {
+		controls, err := s.mixer.ListControls(card.ID)
+		if err != nil {
+			continue
+		}
+		if name := primaryControl(controls); name != "" {
+			primary[card.ID] = name
+		}
+	}
+	return primary
+}
+
+// statusSummary is the response of GET /status.
+type statusSummary struct {
+	Status              string `json:"status"` // "ok" or "degraded"
+	MixerOpen           bool   `json:"mixerOpen"`
+	Cards               int    `json:"cards"`
+	Clients             int    `json:"clients"`
+	MonitorRunning      bool   `json:"monitorRunning"`
+	LastBroadcastAgeMs  *int64 `json:"lastBroadcastAgeMs"` // null before the first broadcast
+	ConsecutiveFailures int    `json:"consecutiveFailures"`
+	BroadcastBytes      int64  `json:"broadcastBytes"` // serialized size of all broadcast events
+	UptimeSeconds       int64  `json:"uptimeSeconds"`
+}
+
+// StatusHandler serves GET /status: one summary of the mixer, monitor and
+// SSE hub for dashboards. The status is "degraded" when the mixer is not
+// open or the monitor's latest polls failed.
+func (s *Server) StatusHandler(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	summary := statusSummary{
+		Status:         "ok",
+		MixerOpen:      s.mixerUnavailableReason() == "",
+		Clients:        s.hub.ClientCount(),
+		BroadcastBytes: s.hub.BroadcastBytes(),
+		UptimeSeconds:  int64(now.Sub(s.started) / time.Second),
+	}
+	if summary.MixerOpen {
+		if cards, err := s.listCards(); err == nil {
+			summary.Cards = len(cards)
+		}
+	}
+	if s.monitor != nil {
+		summary.MonitorRunning = s.monitor.Running()
+		summary.ConsecutiveFailures = s.monitor.ConsecutiveFailures()
+	}
+	if last := s.hub.LastBroadcast(); !last.IsZero() {
+		age := now.Sub(last).Milliseconds()
+		summary.LastBroadcastAgeMs = &age
+	}
+	if !summary.MixerOpen || summary.ConsecutiveFailures > 0 {
+		summary.Status = "degraded"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	_ = json.NewEncoder(w).Encode(summary)
+}
+
+// idleHandleCloser is implemented by mixers that cache per-card handles;
+// *alsa.Mixer does.
+type idleHandleCloser interface {
+	CloseIdleHandles()
+}
+
+// setVolumeCounter is implemented by mixers that count how volume changes
+// were applied; *alsa.Mixer does.
+type setVolumeCounter interface {
+	SetVolumeStats() alsa.SetVolumeStats
+}
+
+// MetricsHandler serves GET /metrics in the Prometheus text format: how
+// volume changes reached the hardware, to tell whether the amixer path or
+// the library fallback is doing the work, and the SSE hub's traffic.
+func (s *Server) MetricsHandler(w http.ResponseWriter, r *http.Request) {
+	var stats alsa.SetVolumeStats
+	if counter, ok := s.hw.(setVolumeCounter); ok {
+		stats = counter.SetVolumeStats()
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	writeMetric(w, "alsamixer_web_amixer_set_volume_total", "counter", "Volume changes applied by amixer.", stats.AmixerSuccess)
+	writeMetric(w, "alsamixer_web_amixer_fallback_total", "counter", "Volume changes amixer failed, retried through the ALSA library.", stats.AmixerFallback)
+	writeMetric(w, "alsamixer_web_library_set_volume_total", "counter", "Volume changes applied by the ALSA library after amixer failed.", stats.LibrarySuccess)
+	writeMetric(w, "alsamixer_web_sse_clients", "gauge", "Connected SSE clients.", uint64(s.hub.ClientCount()))
+	writeMetric(w, "alsamixer_web_sse_broadcast_bytes_total", "counter", "Serialized size of all broadcast events.", uint64(s.hub.BroadcastBytes()))
+}
+
+func writeMetric(w io.Writer, name, kind, help string, value uint64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %d\n", name, help, name, kind, name, value)
+}
+
+// controlEventFilter parses the ?control=<card>:<name> of an /events
+// subscription. The returned filter narrows mixer-update events to that
+// one control and drops those that don't mention it; other events pass
+// through. An empty param subscribes to everything.
+func controlEventFilter(param string) (sse.EventFilter, error) {
+	if param == "" {
+		return nil, nil
+	}
+	cardStr, control, ok := strings.Cut(param, ":")
+	card, err := strconv.ParseUint(cardStr, 10, 0)
+	if !ok || err != nil || control == "" {
+		return nil, fmt.Errorf("invalid control %q: expected <card>:<control name>", param)
+	}
+
+	return func(event sse.Event) (sse.Event, bool) {
+		if event.Type != "mixer-update" {
+			return event, true
+		}
+		data, ok := event.Data.(map[string]interface{})
+		if !ok {
+			return event, false
+		}
+		state, ok := narrowState(data["state"], uint(card), control)
+		if !ok {
+			return event, false
+		}
+		narrowed := make(map[string]interface{}, len(data))
+		for k, v := range data {
+			narrowed[k] = v
+		}
+		narrowed["state"] = state
+		event.Data = narrowed
+		return event, true
+	}, nil
+}
+
+// narrowState returns the part of a mixer-update state holding control on
+// card, and whether it was there. The monitor sends an *alsa.StateSnapshot;
+// handlers send maps keyed by the card ID as a string.
+func narrowState(state interface{}, card uint, control string) (interface{}, bool) {
+	switch st := state.(type) {
+	case *alsa.StateSnapshot:
+		cs, ok := st.Cards[card].Controls[control]
+		if !ok {
+			return nil, false
+		}
+		return &alsa.StateSnapshot{Cards: map[uint]alsa.CardState{
+			card: {Controls: map[string]alsa.ControlState{control: cs}},
+		}}, true
+	case map[string]interface{}:
+		key := strconv.FormatUint(uint64(card), 10)
+		controls, ok := st[key].(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		cs, ok := controls[control]
+		if !ok {
+			return nil, false
+		}
+		return map[string]interface{}{key: map[string]interface{}{control: cs}}, true
+	}
+	return nil, false
+}
+
+// EventsRefreshHandler serves POST /events/refresh?clientId=<id>. It sends
+// the monitor's full current state as a mixer-update to the SSE client that
+// connected with that id only, e.g. after it reconnected and may have
+// missed updates.
+func (s *Server) EventsRefreshHandler(w http.ResponseWriter, r *http.Request) {
+	clientID := r.URL.Query().Get("clientId")
+	if clientID == "" {
+		http.Error(w, "missing clientId", http.StatusBadRequest)
+		return
+	}
+	if s.monitor == nil {
+		reason := s.mixerUnavailableReason()
+		if reason == "" {
+			reason = "monitor not running"
+		}
+		http.Error(w, "mixer not available: "+reason, http.StatusServiceUnavailable)
+		return
+	}
+
+	sent := s.hub.SendTo(clientID, sse.Event{
+		Type: "mixer-update",
+		Data: map[string]interface{}{
+			"state":     s.monitor.StateSince(time.Time{}),
+			"source":    "refresh",
+			"timestamp": time.Now().UnixMilli(),
+		},
+	})
+	if sent == 0 {
+		http.Error(w, "no connected client with that clientId", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,463 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/alsamixer-web/internal/alsa"
+	"github.com/user/alsamixer-web/internal/config"
+	"github.com/user/alsamixer-web/internal/sse"
+)
+
+func TestCapabilitiesCache(t *testing.T) {
+	var c capabilitiesCache
+	builds := 0
+	build := func() ([]byte, error) {
+		builds++
+		return []byte(`{"cards":[]}`), nil
+	}
+
+	body1, etag1, err := c.get(build)
+	if err != nil {
+		t.Fatalf("get() error = %v", err)
+	}
+	body2, etag2, err := c.get(build)
+	if err != nil {
+		t.Fatalf("get() error = %v", err)
+	}
+
+	if builds != 1 {
+		t.Errorf("expected document to be built once, got %d builds", builds)
+	}
+	if string(body1) != string(body2) || etag1 != etag2 {
+		t.Errorf("expected cached document on second request, got %q/%s and %q/%s", body1, etag1, body2, etag2)
+	}
+
+	// Simulate the monitor reporting a control-list change.
+	c.invalidate()
+
+	if _, _, err := c.get(build); err != nil {
+		t.Fatalf("get() error = %v", err)
+	}
+	if builds != 2 {
+		t.Errorf("expected rebuild after invalidation, got %d builds", builds)
+	}
+}
+
+func TestCapabilitiesHandler_ETag(t *testing.T) {
+	cfg := &config.Config{
+		Port:     0,
+		BindAddr: "127.0.0.1",
+	}
+	hub := sse.NewHub()
+	srv := newTestServer(t, cfg, hub)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/capabilities", nil)
+	resp := httptest.NewRecorder()
+	srv.mux.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.Code)
+	}
+	if ct := resp.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected Content-Type application/json, got %q", ct)
+	}
+	etag := resp.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("expected ETag header to be set")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/capabilities", nil)
+	req.Header.Set("If-None-Match", etag)
+	resp = httptest.NewRecorder()
+	srv.mux.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusNotModified {
+		t.Errorf("expected status %d for matching ETag, got %d", http.StatusNotModified, resp.Code)
+	}
+}
+
+func TestRescanHandler(t *testing.T) {
+	cfg := &config.Config{
+		Port:     0,
+		BindAddr: "127.0.0.1",
+	}
+	hub := sse.NewHub()
+	go hub.Run()
+	srv := newTestServer(t, cfg, hub)
+	srv.useMixer(&fakeMixer{})
+
+	ts := httptest.NewServer(srv.mux)
+	t.Cleanup(ts.Close)
+	events := subscribeEvents(t, ts.URL, hub)
+
+	// Prime the capabilities cache so we can see the rescan bypass it.
+	if _, _, err := srv.capabilities.get(func() ([]byte, error) { return []byte(`{"stale":true}`), nil }); err != nil {
+		t.Fatalf("priming cache: %v", err)
+	}
+
+	before := time.Now()
+	resp, err := http.Post(ts.URL+"/api/rescan", "", nil)
+	if err != nil {
+		t.Fatalf("POST /api/rescan: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	var body struct {
+		Cards []cardSummary `json:"cards"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if body.Cards == nil {
+		t.Error("expected a cards array in the response")
+	}
+
+	srv.capabilities.mu.Lock()
+	cached := srv.capabilities.body
+	srv.capabilities.mu.Unlock()
+	if cached != nil {
+		t.Errorf("expected rescan to drop cached capabilities, still have %s", cached)
+	}
+
+	data := waitForEvent(t, events, "card-list-change", time.Second)
+	var payload struct {
+		Timestamp int64 `json:"timestamp"`
+	}
+	if err := json.Unmarshal([]byte(data), &payload); err != nil {
+		t.Fatalf("decoding event data %q: %v", data, err)
+	}
+	if payload.Timestamp < before.UnixMilli() {
+		t.Errorf("expected a unix millisecond timestamp, got %d", payload.Timestamp)
+	}
+}
+
+// noCardsMixer is a fakeMixer whose cards cannot be listed.
+type noCardsMixer struct {
+	fakeMixer
+}
+
+func (m *noCardsMixer) ListCards() ([]alsa.Card, error) {
+	return nil, fmt.Errorf("failed to enumerate cards")
+}
+
+func TestRescanHandlerListFailure(t *testing.T) {
+	cfg := &config.Config{
+		Port:     0,
+		BindAddr: "127.0.0.1",
+	}
+	hub := sse.NewHub()
+	go hub.Run()
+	srv := newTestServer(t, cfg, hub)
+	srv.useMixer(&noCardsMixer{})
+
+	ts := httptest.NewServer(srv.mux)
+	t.Cleanup(ts.Close)
+	events := subscribeEvents(t, ts.URL, hub)
+
+	resp, err := http.Post(ts.URL+"/api/rescan", "", nil)
+	if err != nil {
+		t.Fatalf("POST /api/rescan: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("expected status %d, got %d", http.StatusInternalServerError, resp.StatusCode)
+	}
+
+	timeout := time.After(200 * time.Millisecond)
+	for done := false; !done; {
+		select {
+		case line := <-events:
+			if line == "event: card-list-change" {
+				t.Fatal("expected no card-list-change after a failed rescan")
+			}
+		case <-timeout:
+			done = true
+		}
+	}
+}
+
+func TestStateHandler(t *testing.T) {
+	cfg := &config.Config{
+		Port:     0,
+		BindAddr: "127.0.0.1",
+	}
+	srv := newTestServer(t, cfg, sse.NewHub())
+	if srv.monitor == nil {
+		t.Skip("ALSA monitor not available on this platform")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/state?since=yesterday", nil)
+	resp := httptest.NewRecorder()
+	srv.mux.ServeHTTP(resp, req)
+	if resp.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d for invalid since, got %d", http.StatusBadRequest, resp.Code)
+	}
+
+	since := time.Now().UnixMilli()
+	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/state?since=%d", since), nil)
+	resp = httptest.NewRecorder()
+	srv.mux.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.Code)
+	}
+	var body struct {
+		State struct {
+			Cards map[string]interface{}
+		} `json:"state"`
+		Timestamp int64 `json:"timestamp"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if len(body.State.Cards) != 0 {
+		t.Errorf("expected an empty change set, got %v", body.State.Cards)
+	}
+	if body.Timestamp < since {
+		t.Errorf("expected response timestamp >= %d, got %d", since, body.Timestamp)
+	}
+}
+
+func TestStatusHandler(t *testing.T) {
+	cfg := &config.Config{
+		Port:     0,
+		BindAddr: "127.0.0.1",
+	}
+	hub := sse.NewHub()
+	srv := newTestServer(t, cfg, hub)
+	srv.mixer = &fakeMixer{}
+	go hub.Run()
+
+	ts := httptest.NewServer(srv.mux)
+	t.Cleanup(ts.Close)
+	subscribeEvents(t, ts.URL, hub)
+
+	resp, err := http.Get(ts.URL + "/status")
+	if err != nil {
+		t.Fatalf("GET /status failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var summary statusSummary
+	if err := json.NewDecoder(resp.Body).Decode(&summary); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if summary.Status != "ok" || !summary.MixerOpen {
+		t.Errorf("expected an ok status with the mixer open, got %+v", summary)
+	}
+	if summary.Clients != 1 {
+		t.Errorf("expected 1 client, got %d", summary.Clients)
+	}
+	if summary.Cards != 1 {
+		t.Errorf("expected the fake mixer's card, got %d cards", summary.Cards)
+	}
+	if summary.LastBroadcastAgeMs != nil {
+		t.Errorf("expected no broadcast age before any broadcast, got %d", *summary.LastBroadcastAgeMs)
+	}
+}
+
+func TestEventsRefreshHandler(t *testing.T) {
+	cfg := &config.Config{
+		Port:     0,
+		BindAddr: "127.0.0.1",
+	}
+	hub := sse.NewHub()
+	srv := newTestServer(t, cfg, hub)
+	go hub.Run()
+
+	srv.monitor = alsa.NewMonitor(&fakeMixer{}, hub, "")
+	t.Cleanup(srv.monitor.Stop)
+	srv.monitor.Rescan()
+
+	ts := httptest.NewServer(srv.mux)
+	t.Cleanup(ts.Close)
+	named := subscribeEventsAs(t, ts.URL, hub, "tab-1")
+	other := subscribeEventsAs(t, ts.URL, hub, "tab-2")
+
+	resp, err := http.Post(ts.URL+"/events/refresh?clientId=tab-1", "", nil)
+	if err != nil {
+		t.Fatalf("POST /events/refresh failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected status %d, got %d", http.StatusNoContent, resp.StatusCode)
+	}
+
+	data := waitForEvent(t, named, "mixer-update", time.Second)
+	var payload struct {
+		Source string             `json:"source"`
+		State  alsa.StateSnapshot `json:"state"`
+	}
+	if err := json.Unmarshal([]byte(data), &payload); err != nil {
+		t.Fatalf("decoding event data %q: %v", data, err)
+	}
+	if payload.Source != "refresh" {
+		t.Errorf("expected source refresh, got %q", payload.Source)
+	}
+	if _, ok := payload.State.Cards[0].Controls["Master Playback Volume"]; !ok {
+		t.Errorf("expected a full snapshot including Master, got %+v", payload.State)
+	}
+
+	select {
+	case line := <-other:
+		if line == "event: mixer-update" {
+			t.Error("expected the snapshot to reach only the requesting client")
+		}
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	resp, err = http.Post(ts.URL+"/events/refresh?clientId=nobody", "", nil)
+	if err != nil {
+		t.Fatalf("POST /events/refresh failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status %d for an unknown client, got %d", http.StatusNotFound, resp.StatusCode)
+	}
+}
+
+func TestStateHandlerReportsPrimary(t *testing.T) {
+	cfg := &config.Config{
+		Port:     0,
+		BindAddr: "127.0.0.1",
+	}
+	hub := sse.NewHub()
+	go hub.Run()
+	srv := newTestServer(t, cfg, hub)
+	fm := &fakeMixer{}
+	srv.mixer = fm
+	srv.monitor = alsa.NewMonitor(fm, hub, "")
+	t.Cleanup(srv.monitor.Stop)
+	srv.monitor.Rescan()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/state", nil)
+	resp := httptest.NewRecorder()
+	srv.mux.ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.Code)
+	}
+
+	var body struct {
+		Primary map[string]string `json:"primary"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if body.Primary["0"] != "Master Playback Volume" {
+		t.Errorf("expected Master as card 0's primary control, got %v", body.Primary)
+	}
+}
+
+// countedFakeMixer is a fakeMixer that reports how its volume changes were
+// applied, like *alsa.Mixer.
+type countedFakeMixer struct {
+	*fakeMixer
+	stats alsa.SetVolumeStats
+}
+
+func (f countedFakeMixer) SetVolumeStats() alsa.SetVolumeStats { return f.stats }
+
+func TestMetricsHandler(t *testing.T) {
+	cfg := &config.Config{
+		Port:     0,
+		BindAddr: "127.0.0.1",
+	}
+	srv := newTestServer(t, cfg, sse.NewHub())
+	srv.useMixer(countedFakeMixer{
+		fakeMixer: &fakeMixer{},
+		stats:     alsa.SetVolumeStats{AmixerSuccess: 7, AmixerFallback: 2, LibrarySuccess: 1},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	resp := httptest.NewRecorder()
+	srv.mux.ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.Code)
+	}
+
+	body := resp.Body.String()
+	for _, want := range []string{
+		"# TYPE alsamixer_web_amixer_set_volume_total counter\nalsamixer_web_amixer_set_volume_total 7\n",
+		"alsamixer_web_amixer_fallback_total 2\n",
+		"alsamixer_web_library_set_volume_total 1\n",
+		"alsamixer_web_sse_clients 0\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected metrics to contain %q, got:\n%s", want, body)
+		}
+	}
+}
+
+func TestEventsControlSubscription(t *testing.T) {
+	cfg := &config.Config{
+		Port:     0,
+		BindAddr: "127.0.0.1",
+	}
+	hub := sse.NewHub()
+	srv := newTestServer(t, cfg, hub)
+	go hub.Run()
+
+	ts := httptest.NewServer(srv.mux)
+	t.Cleanup(ts.Close)
+	events := subscribeEventsWith(t, ts.URL, hub, url.Values{"control": {"0:Master Playback Volume"}})
+
+	// Another control changes first, on the same card and on another one,
+	// then the subscribed control changes.
+	hub.Broadcast(sse.Event{Type: "mixer-update", Data: map[string]interface{}{
+		"state":  map[string]interface{}{"0": map[string]interface{}{"Headphone Playback Volume": map[string]interface{}{"Volume": []int{10}}}},
+		"source": "handler",
+	}})
+	hub.Broadcast(sse.Event{Type: "mixer-update", Data: map[string]interface{}{
+		"state":  &alsa.StateSnapshot{Cards: map[uint]alsa.CardState{1: {Controls: map[string]alsa.ControlState{"Master Playback Volume": {Volume: []int{20}}}}}},
+		"source": "monitor",
+	}})
+	hub.Broadcast(sse.Event{Type: "mixer-update", Data: map[string]interface{}{
+		"state": &alsa.StateSnapshot{Cards: map[uint]alsa.CardState{0: {Controls: map[string]alsa.ControlState{
+			"Master Playback Volume":    {Volume: []int{30}},
+			"Headphone Playback Volume": {Volume: []int{40}},
+		}}}},
+		"source": "monitor",
+	}})
+
+	data := waitForEvent(t, events, "mixer-update", time.Second)
+	var payload struct {
+		State alsa.StateSnapshot `json:"state"`
+	}
+	if err := json.Unmarshal([]byte(data), &payload); err != nil {
+		t.Fatalf("decoding event data %q: %v", data, err)
+	}
+	controls := payload.State.Cards[0].Controls
+	if len(payload.State.Cards) != 1 || len(controls) != 1 || controls["Master Playback Volume"].Volume[0] != 30 {
+		t.Fatalf("expected only card 0's Master at 30%%, got %+v", payload.State)
+	}
+
+	select {
+	case line := <-events:
+		if line == "event: mixer-update" {
+			t.Error("expected no further updates for the subscribed control")
+		}
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	resp, err := http.Get(ts.URL + "/events?control=Master")
+	if err != nil {
+		t.Fatalf("GET /events failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status %d for a control without a card, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
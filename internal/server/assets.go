@@ -0,0 +1,84 @@
+package server
+
+import (
+	"encoding/json"
+	"io/fs"
+	"log"
+	"net/http"
+
+	"github.com/user/alsamixer-web/web"
+)
+
+// appName is the title shown in the page header and used when the app is
+// installed to a home screen.
+const appName = "ALSA Mixer Web"
+
+// webManifest is the JSON document served by GET /manifest.json.
+type webManifest struct {
+	Name            string         `json:"name"`
+	ShortName       string         `json:"short_name"`
+	StartURL        string         `json:"start_url"`
+	Display         string         `json:"display"`
+	BackgroundColor string         `json:"background_color"`
+	ThemeColor      string         `json:"theme_color"`
+	Icons           []manifestIcon `json:"icons"`
+}
+
+type manifestIcon struct {
+	Src   string `json:"src"`
+	Sizes string `json:"sizes"`
+	Type  string `json:"type"`
+}
+
+// FaviconHandler serves the embedded favicon at the path browsers request
+// it from by default.
+func (s *Server) FaviconHandler(w http.ResponseWriter, r *http.Request) {
+	icon, err := fs.ReadFile(web.StaticFS(), "favicon.ico")
+	if err != nil {
+		log.Printf("failed to read embedded favicon: %v", err)
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/x-icon")
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	_, _ = w.Write(icon)
+}
+
+// ManifestHandler serves a web app manifest so the mixer can be installed
+// as a standalone app on mobile devices. Browsers only offer to install it
+// with 192px and 512px PNG icons.
+func (s *Server) ManifestHandler(w http.ResponseWriter, r *http.Request) {
+	manifest := webManifest{
+		Name:            appName,
+		ShortName:       "Mixer",
+		StartURL:        "/",
+		Display:         "standalone",
+		BackgroundColor: "#000000",
+		ThemeColor:      "#2e3239",
+		Icons: []manifestIcon{
+			{Src: "/favicon.ico", Sizes: "32x32", Type: "image/x-icon"},
+			{Src: "/static/icons/icon-192.png", Sizes: "192x192", Type: "image/png"},
+			{Src: "/static/icons/icon-512.png", Sizes: "512x512", Type: "image/png"},
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/manifest+json")
+	_ = json.NewEncoder(w).Encode(manifest)
+}
+
+// ServiceWorkerHandler serves the embedded service worker from the site root
+// so its scope covers the whole app.
+func (s *Server) ServiceWorkerHandler(w http.ResponseWriter, r *http.Request) {
+	script, err := fs.ReadFile(web.StaticFS(), "sw.js")
+	if err != nil {
+		log.Printf("failed to read embedded service worker: %v", err)
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/javascript")
+	// Browsers check for a new worker on navigation; don't let caches hide it.
+	w.Header().Set("Cache-Control", "no-cache")
+	_, _ = w.Write(script)
+}
@@ -0,0 +1,117 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/user/alsamixer-web/internal/config"
+	"github.com/user/alsamixer-web/internal/sse"
+)
+
+func TestFaviconHandler(t *testing.T) {
+	cfg := &config.Config{
+		Port:     0,
+		BindAddr: "127.0.0.1",
+	}
+	srv := newTestServer(t, cfg, sse.NewHub())
+
+	req := httptest.NewRequest(http.MethodGet, "/favicon.ico", nil)
+	resp := httptest.NewRecorder()
+	srv.mux.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.Code)
+	}
+	if ct := resp.Header().Get("Content-Type"); !strings.HasPrefix(ct, "image/") {
+		t.Errorf("expected an image Content-Type, got %q", ct)
+	}
+	if resp.Body.Len() == 0 {
+		t.Error("expected a non-empty favicon body")
+	}
+}
+
+func TestManifestHandler(t *testing.T) {
+	cfg := &config.Config{
+		Port:     0,
+		BindAddr: "127.0.0.1",
+	}
+	srv := newTestServer(t, cfg, sse.NewHub())
+
+	req := httptest.NewRequest(http.MethodGet, "/manifest.json", nil)
+	resp := httptest.NewRecorder()
+	srv.mux.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.Code)
+	}
+
+	var manifest webManifest
+	if err := json.NewDecoder(resp.Body).Decode(&manifest); err != nil {
+		t.Fatalf("expected valid JSON manifest: %v", err)
+	}
+	if manifest.Name != appName {
+		t.Errorf("expected manifest name %q, got %q", appName, manifest.Name)
+	}
+	if manifest.StartURL != "/" {
+		t.Errorf("expected start_url /, got %q", manifest.StartURL)
+	}
+
+	// Installing needs 192px and 512px PNG icons that are really served.
+	sizes := map[string]bool{}
+	for _, icon := range manifest.Icons {
+		if icon.Type != "image/png" {
+			continue
+		}
+		req := httptest.NewRequest(http.MethodGet, icon.Src, nil)
+		resp := httptest.NewRecorder()
+		srv.mux.ServeHTTP(resp, req)
+		if resp.Code != http.StatusOK {
+			t.Errorf("expected icon %s to be served, got status %d", icon.Src, resp.Code)
+			continue
+		}
+		img, err := png.DecodeConfig(resp.Body)
+		if err != nil {
+			t.Errorf("expected icon %s to be a PNG: %v", icon.Src, err)
+			continue
+		}
+		if want := fmt.Sprintf("%dx%d", img.Width, img.Height); icon.Sizes != want {
+			t.Errorf("expected icon %s to be %s as listed, got %s", icon.Src, icon.Sizes, want)
+		}
+		sizes[icon.Sizes] = true
+	}
+	if !sizes["192x192"] || !sizes["512x512"] {
+		t.Errorf("expected 192x192 and 512x512 PNG icons, got %+v", manifest.Icons)
+	}
+}
+
+func TestServiceWorkerHandler(t *testing.T) {
+	cfg := &config.Config{
+		Port:     0,
+		BindAddr: "127.0.0.1",
+	}
+	srv := newTestServer(t, cfg, sse.NewHub())
+
+	req := httptest.NewRequest(http.MethodGet, "/sw.js", nil)
+	resp := httptest.NewRecorder()
+	srv.mux.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.Code)
+	}
+	if ct := resp.Header().Get("Content-Type"); ct != "application/javascript" {
+		t.Errorf("expected Content-Type application/javascript, got %q", ct)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	resp = httptest.NewRecorder()
+	srv.mux.ServeHTTP(resp, req)
+
+	if !strings.Contains(resp.Body.String(), "/sw.js") {
+		t.Error("expected the page to register /sw.js")
+	}
+}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"log"
+	"sync"
+)
+
+type dryRunKey struct {
+	card    uint
+	control string
+}
+
+// dryRunMixer wraps a mixer for --dry-run: writes are logged and remembered
+// instead of reaching the hardware, and reads of a written control return
+// the remembered value so handlers broadcast the intended state.
+type dryRunMixer struct {
+	mixer
+
+	mu      sync.Mutex
+	volumes map[dryRunKey][]int
+	raws    map[dryRunKey][]int
+	dbs     map[dryRunKey][]float64
+	mutes   map[dryRunKey]bool
+}
+
+func newDryRunMixer(m mixer) *dryRunMixer {
+	return &dryRunMixer{
+		mixer:   m,
+		volumes: make(map[dryRunKey][]int),
+		raws:    make(map[dryRunKey][]int),
+		dbs:     make(map[dryRunKey][]float64),
+		mutes:   make(map[dryRunKey]bool),
+	}
+}
+
+func (d *dryRunMixer) SetVolume(card uint, control string, values []int) error {
+	log.Printf("[dry-run] would set %s on card %d to %v", control, card, values)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.volumes[dryRunKey{card, control}] = append([]int(nil), values...)
+	return nil
+}
+
+func (d *dryRunMixer) GetVolume(card uint, control string) ([]int, error) {
+	d.mu.Lock()
+	values, ok := d.volumes[dryRunKey{card, control}]
+	d.mu.Unlock()
+	if ok {
+		return values, nil
+	}
+	return d.mixer.GetVolume(card, control)
+}
+
+func (d *dryRunMixer) SetRawVolume(card uint, control string, values []int) error {
+	log.Printf("[dry-run] would set %s on card %d to raw %v", control, card, values)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.raws[dryRunKey{card, control}] = append([]int(nil), values...)
+	return nil
+}
+
+func (d *dryRunMixer) GetRawVolume(card uint, control string) ([]int, error) {
+	d.mu.Lock()
+	values, ok := d.raws[dryRunKey{card, control}]
+	d.mu.Unlock()
+	if ok {
+		return values, nil
+	}
+	return d.mixer.GetRawVolume(card, control)
+}
+
+func (d *dryRunMixer) SetVolumeDB(card uint, control string, values []float64) error {
+	log.Printf("[dry-run] would set %s on card %d to %vdB", control, card, values)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.dbs[dryRunKey{card, control}] = append([]float64(nil), values...)
+	return nil
+}
+
+func (d *dryRunMixer) GetVolumeDB(card uint, control string) ([]float64, error) {
+	d.mu.Lock()
+	values, ok := d.dbs[dryRunKey{card, control}]
+	d.mu.Unlock()
+	if ok {
+		return values, nil
+	}
+	return d.mixer.GetVolumeDB(card, control)
+}
+
+func (d *dryRunMixer) SetMute(card uint, control string, muted bool) error {
+	log.Printf("[dry-run] would set %s on card %d to muted=%v", control, card, muted)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.mutes[dryRunKey{card, control}] = muted
+	return nil
+}
+
+func (d *dryRunMixer) GetMute(card uint, control string) (bool, error) {
+	d.mu.Lock()
+	muted, ok := d.mutes[dryRunKey{card, control}]
+	d.mu.Unlock()
+	if ok {
+		return muted, nil
+	}
+	return d.mixer.GetMute(card, control)
+}
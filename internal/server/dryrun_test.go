@@ -0,0 +1,65 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/alsamixer-web/internal/config"
+	"github.com/user/alsamixer-web/internal/sse"
+)
+
+func TestDryRunSkipsHardwareWrites(t *testing.T) {
+	cfg := &config.Config{
+		Port:     0,
+		BindAddr: "127.0.0.1",
+		DryRun:   true,
+	}
+	hub := sse.NewHub()
+	go hub.Run()
+	srv := newTestServer(t, cfg, hub)
+
+	fm := &fakeMixer{}
+	srv.useMixer(fm)
+
+	ts := httptest.NewServer(srv.mux)
+	t.Cleanup(ts.Close)
+	events := subscribeEvents(t, ts.URL, hub)
+
+	form := url.Values{}
+	form.Set("card", "0")
+	form.Set("control", "Master Playback Volume")
+	form.Set("volume", "30")
+	req := httptest.NewRequest(http.MethodPost, "/control/volume", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp := httptest.NewRecorder()
+	srv.mux.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusNoContent {
+		t.Fatalf("expected status %d, got %d", http.StatusNoContent, resp.Code)
+	}
+	if resp.Header().Get("X-Dry-Run") != "true" {
+		t.Error("expected the response to be marked as a dry run")
+	}
+	if fm.called {
+		t.Fatal("expected SetVolume not to reach the mixer in dry-run mode")
+	}
+
+	data := waitForEvent(t, events, "mixer-update", time.Second)
+	var payload struct {
+		State map[string]map[string]struct {
+			Volume []int
+		} `json:"state"`
+	}
+	if err := json.Unmarshal([]byte(data), &payload); err != nil {
+		t.Fatalf("decoding event data %q: %v", data, err)
+	}
+	got := payload.State["0"]["Master Playback Volume"].Volume
+	if len(got) != 1 || got[0] != 30 {
+		t.Errorf("expected the intended volume 30 to be broadcast, got %v", got)
+	}
+}
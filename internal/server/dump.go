@@ -0,0 +1,26 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/pprof"
+)
+
+// DumpState writes the goroutine count and stacks and the monitor's
+// state to w. The binary calls it on SIGUSR1 to diagnose a server that
+// has stopped responding or stopped pushing updates, without killing it.
+// The stacks come first, so they are written even if a deadlock blocks
+// the rest.
+func (s *Server) DumpState(w io.Writer) {
+	fmt.Fprintf(w, "goroutines: %d\n", runtime.NumGoroutine())
+	_ = pprof.Lookup("goroutine").WriteTo(w, 2)
+	if s.monitor != nil {
+		s.monitor.WriteState(w)
+	} else {
+		fmt.Fprintln(w, "monitor: not running")
+	}
+	if s.hub != nil {
+		fmt.Fprintf(w, "sse clients: %d\n", s.hub.ClientCount())
+	}
+}
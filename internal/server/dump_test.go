@@ -0,0 +1,40 @@
+package server
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/user/alsamixer-web/internal/alsa"
+	"github.com/user/alsamixer-web/internal/config"
+	"github.com/user/alsamixer-web/internal/sse"
+)
+
+func TestDumpState(t *testing.T) {
+	hub := sse.NewHub()
+	go hub.Run()
+	srv := newTestServer(t, &config.Config{Port: 0, BindAddr: "127.0.0.1"}, hub)
+	fm := &fakeMixer{readBack: []int{42, 42}}
+	srv.useMixer(fm)
+	srv.monitor = alsa.NewMonitor(fm, hub, "")
+	t.Cleanup(srv.monitor.Stop)
+	srv.monitor.Rescan()
+
+	var buf bytes.Buffer
+	srv.DumpState(&buf)
+	dump := buf.String()
+
+	if !regexp.MustCompile(`(?m)^goroutines: [1-9][0-9]*$`).MatchString(dump) {
+		t.Errorf("expected a goroutine count in the dump, got:\n%s", dump)
+	}
+	if !strings.Contains(dump, "monitor: running=false") {
+		t.Errorf("expected the monitor status in the dump, got:\n%s", dump)
+	}
+	if !strings.Contains(dump, `monitor: card 0 "Master Playback Volume" volume=[42 42]`) {
+		t.Errorf("expected the monitor's last reading in the dump, got:\n%s", dump)
+	}
+	if !strings.Contains(dump, "goroutine ") || !strings.Contains(dump, "TestDumpState") {
+		t.Errorf("expected goroutine stacks in the dump, got:\n%s", dump)
+	}
+}
@@ -0,0 +1,218 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/user/alsamixer-web/internal/alsa"
+)
+
+// exportVersion is bumped if the export document changes incompatibly.
+const exportVersion = 1
+
+// maxImportSize bounds the body accepted by POST /api/import.
+const maxImportSize = 1 << 20
+
+// mixerExport is the document served by GET /api/export and accepted by
+// POST /api/import.
+type mixerExport struct {
+	Version    int          `json:"version"`
+	ExportedAt time.Time    `json:"exportedAt"`
+	Cards      []cardExport `json:"cards"`
+}
+
+type cardExport struct {
+	ID       uint            `json:"id"`
+	Name     string          `json:"name"`
+	LongName string          `json:"longName,omitempty"`
+	Controls []controlExport `json:"controls"`
+}
+
+// controlExport holds the value of one control: Volume for integer
+// controls, Muted for switches.
+type controlExport struct {
+	Name   string `json:"name"`
+	Volume []int  `json:"volume,omitempty"`
+	Muted  *bool  `json:"muted,omitempty"`
+}
+
+// importSkip explains why part of an import could not be applied.
+type importSkip struct {
+	Card    string `json:"card"`
+	Control string `json:"control,omitempty"`
+	Reason  string `json:"reason"`
+}
+
+type importResult struct {
+	Applied int          `json:"applied"`
+	Skipped []importSkip `json:"skipped"`
+}
+
+// ExportHandler serves GET /api/export: every exposed card's controls and
+// their current values as one JSON document.
+func (s *Server) ExportHandler(w http.ResponseWriter, r *http.Request) {
+	if reason := s.mixerUnavailableReason(); reason != "" {
+		http.Error(w, "mixer not available: "+reason, http.StatusServiceUnavailable)
+		return
+	}
+
+	cards, err := s.listCards()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to list cards: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	doc := mixerExport{
+		Version:    exportVersion,
+		ExportedAt: time.Now().UTC(),
+		Cards:      make([]cardExport, 0, len(cards)),
+	}
+	for _, card := range cards {
+		controls, err := s.mixer.ListControls(card.ID)
+		if err != nil {
+			log.Printf("export: failed to list controls for card %d: %v", card.ID, err)
+			continue
+		}
+
+		ce := cardExport{ID: card.ID, Name: card.Name, LongName: card.LongName, Controls: []controlExport{}}
+		for _, ctrl := range controls {
+			switch ctrl.Type {
+			case "integer":
+				volumes, err := s.mixer.GetVolume(card.ID, ctrl.Name)
+				if err != nil {
+					continue
+				}
+				ce.Controls = append(ce.Controls, controlExport{Name: ctrl.Name, Volume: volumes})
+			case "boolean":
+				muted, err := s.mixer.GetMute(card.ID, ctrl.Name)
+				if err != nil {
+					continue
+				}
+				ce.Controls = append(ce.Controls, controlExport{Name: ctrl.Name, Muted: &muted})
+			}
+		}
+		doc.Cards = append(doc.Cards, ce)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="alsamixer-web-export.json"`)
+	_ = json.NewEncoder(w).Encode(doc)
+}
+
+// matchExportedCard finds the present card an exported card should be
+// applied to: the same index if the name still matches, otherwise the
+// first card with the same long name or name.
+func matchExportedCard(exported cardExport, cards []alsa.Card) (alsa.Card, bool) {
+	for _, c := range cards {
+		if c.ID == exported.ID && c.Name == exported.Name {
+			return c, true
+		}
+	}
+	if exported.LongName != "" {
+		for _, c := range cards {
+			if c.LongName == exported.LongName {
+				return c, true
+			}
+		}
+	}
+	for _, c := range cards {
+		if c.Name == exported.Name {
+			return c, true
+		}
+	}
+	return alsa.Card{}, false
+}
+
+// ImportHandler serves POST /api/import. It applies a document produced by
+// GET /api/export, matching cards by name when their indexes differ, and
+// reports every card or control that could not be applied.
+func (s *Server) ImportHandler(w http.ResponseWriter, r *http.Request) {
+	if reason := s.mixerUnavailableReason(); reason != "" {
+		http.Error(w, "mixer not available: "+reason, http.StatusServiceUnavailable)
+		return
+	}
+
+	var doc mixerExport
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxImportSize)).Decode(&doc); err != nil {
+		http.Error(w, fmt.Sprintf("invalid import document: %v", err), http.StatusBadRequest)
+		return
+	}
+	if doc.Version != exportVersion {
+		http.Error(w, fmt.Sprintf("unsupported export version %d", doc.Version), http.StatusBadRequest)
+		return
+	}
+
+	cards, err := s.listCards()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to list cards: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	m := s.mixer
+
+	// Report the imported state once it is fully applied, and don't let a
+	// fade in progress overwrite it.
+	defer s.suspendMonitor()()
+	s.ramps.cancelAll()
+
+	result := importResult{Skipped: []importSkip{}}
+	for _, exported := range doc.Cards {
+		card, ok := matchExportedCard(exported, cards)
+		if !ok {
+			result.Skipped = append(result.Skipped, importSkip{Card: exported.Name, Reason: "no matching card"})
+			continue
+		}
+
+		controls, err := m.ListControls(card.ID)
+		if err != nil {
+			result.Skipped = append(result.Skipped, importSkip{Card: exported.Name, Reason: err.Error()})
+			continue
+		}
+		present := make(map[string]alsa.Control, len(controls))
+		for _, ctrl := range controls {
+			present[ctrl.Name] = ctrl
+		}
+
+		for _, ctrl := range exported.Controls {
+			target, ok := present[ctrl.Name]
+			if !ok {
+				result.Skipped = append(result.Skipped, importSkip{Card: exported.Name, Control: ctrl.Name, Reason: "control not found"})
+				continue
+			}
+			if ctrl.Volume != nil {
+				err := checkChannelCount(target, ctrl.Volume)
+				if err == nil && s.config.StrictVolume {
+					err = checkVolumeRange(ctrl.Volume)
+				}
+				if err != nil {
+					result.Skipped = append(result.Skipped, importSkip{Card: exported.Name, Control: ctrl.Name, Reason: err.Error()})
+					continue
+				}
+			}
+
+			var err error
+			switch {
+			case ctrl.Volume != nil:
+				err = m.SetVolume(card.ID, ctrl.Name, ctrl.Volume)
+			case ctrl.Muted != nil:
+				err = m.SetMute(card.ID, ctrl.Name, *ctrl.Muted)
+			default:
+				continue
+			}
+			if err != nil {
+				result.Skipped = append(result.Skipped, importSkip{Card: exported.Name, Control: ctrl.Name, Reason: err.Error()})
+				continue
+			}
+			result.Applied++
+		}
+	}
+
+	log.Printf("[POST /api/import] applied %d controls, skipped %d", result.Applied, len(result.Skipped))
+
+	// The monitor picks up the new values and broadcasts them once resumed.
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(result)
+}
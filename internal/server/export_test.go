@@ -0,0 +1,135 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/user/alsamixer-web/internal/config"
+	"github.com/user/alsamixer-web/internal/sse"
+)
+
+func TestExportImportRoundTrip(t *testing.T) {
+	cfg := &config.Config{
+		Port:     0,
+		BindAddr: "127.0.0.1",
+	}
+	srv := newTestServer(t, cfg, sse.NewHub())
+
+	fm := &fakeMixer{}
+	srv.useMixer(fm)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/export", nil)
+	resp := httptest.NewRecorder()
+	srv.mux.ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.Code)
+	}
+
+	var doc mixerExport
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		t.Fatalf("failed to decode export: %v", err)
+	}
+	if len(doc.Cards) != 1 || doc.Cards[0].Name != "Test Card" {
+		t.Fatalf("expected the fake card to be exported, got %+v", doc.Cards)
+	}
+	exported := doc.Cards[0].Controls
+	if len(exported) != 2 || exported[0].Name != "Master Playback Volume" || len(exported[0].Volume) != 2 || exported[0].Volume[0] != 75 {
+		t.Fatalf("expected Master volume [75 75] to be exported, got %+v", exported)
+	}
+	if exported[1].Muted == nil || *exported[1].Muted {
+		t.Fatalf("expected Master switch to be exported as unmuted, got %+v", exported[1])
+	}
+
+	// Apply it to a machine where the same card has another index, along
+	// with a control and a card this one doesn't have.
+	doc.Cards[0].ID = 3
+	doc.Cards[0].Controls[0].Volume = []int{40, 40}
+	doc.Cards[0].Controls = append(doc.Cards[0].Controls, controlExport{Name: "Surround Playback Volume", Volume: []int{10}})
+	doc.Cards = append(doc.Cards, cardExport{ID: 1, Name: "HDMI"})
+
+	body, _ := json.Marshal(doc)
+	req = httptest.NewRequest(http.MethodPost, "/api/import", bytes.NewReader(body))
+	resp = httptest.NewRecorder()
+	srv.mux.ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+
+	var result importResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode import result: %v", err)
+	}
+	if result.Applied != 2 {
+		t.Errorf("expected 2 applied controls, got %d", result.Applied)
+	}
+	if fm.card != 0 || fm.control != "Master Playback Volume" || len(fm.values) != 2 || fm.values[0] != 40 {
+		t.Errorf("expected Master on card 0 to be set to [40 40], got card %d %s %v", fm.card, fm.control, fm.values)
+	}
+
+	skipped := map[string]string{}
+	for _, s := range result.Skipped {
+		skipped[s.Card+"/"+s.Control] = s.Reason
+	}
+	if skipped["Test Card/Surround Playback Volume"] != "control not found" {
+		t.Errorf("expected the missing control to be reported, got %v", result.Skipped)
+	}
+	if skipped["HDMI/"] != "no matching card" {
+		t.Errorf("expected the missing card to be reported, got %v", result.Skipped)
+	}
+}
+
+func TestImportRejectsBadDocument(t *testing.T) {
+	cfg := &config.Config{
+		Port:     0,
+		BindAddr: "127.0.0.1",
+	}
+	srv := newTestServer(t, cfg, sse.NewHub())
+	srv.mixer = &fakeMixer{}
+
+	for _, body := range []string{`not json`, `{"version": 99, "cards": []}`} {
+		req := httptest.NewRequest(http.MethodPost, "/api/import", bytes.NewReader([]byte(body)))
+		resp := httptest.NewRecorder()
+		srv.mux.ServeHTTP(resp, req)
+		if resp.Code != http.StatusBadRequest {
+			t.Errorf("expected status %d for %q, got %d", http.StatusBadRequest, body, resp.Code)
+		}
+	}
+}
+
+func TestImportCancelsRamps(t *testing.T) {
+	cfg := &config.Config{
+		Port:     0,
+		BindAddr: "127.0.0.1",
+	}
+	srv := newTestServer(t, cfg, sse.NewHub())
+
+	fm := &fakeMixer{}
+	srv.useMixer(fm)
+
+	if resp := postVolume(srv, "0", "2000"); resp.Code != http.StatusAccepted {
+		t.Fatalf("expected status %d, got %d", http.StatusAccepted, resp.Code)
+	}
+	time.Sleep(3 * rampInterval)
+
+	doc := mixerExport{Version: exportVersion, Cards: []cardExport{{ID: 0, Name: "Test Card", Controls: []controlExport{
+		{Name: "Master Playback Volume", Volume: []int{40, 40}},
+	}}}}
+	body, _ := json.Marshal(doc)
+	req := httptest.NewRequest(http.MethodPost, "/api/import", bytes.NewReader(body))
+	resp := httptest.NewRecorder()
+	srv.mux.ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+	time.Sleep(3 * rampInterval)
+
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+	if last := fm.history[len(fm.history)-1]; len(last) != 2 || last[0] != 40 {
+		t.Errorf("expected the import to win over the ramp, history %v", fm.history)
+	}
+}
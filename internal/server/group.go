@@ -0,0 +1,154 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+
+	"github.com/user/alsamixer-web/internal/config"
+)
+
+// groupState is the document served for a --group: its aggregate volume
+// and each member's own.
+type groupState struct {
+	Name    string             `json:"name"`
+	Volume  int                `json:"volume"`
+	Members []groupMemberState `json:"members"`
+}
+
+type groupMemberState struct {
+	Card    uint    `json:"card"`
+	Control string  `json:"control"`
+	Scale   float64 `json:"scale"`
+	Volume  int     `json:"volume"`
+}
+
+// memberVolume maps a group volume to a member scaled by scale.
+func memberVolume(volume int, scale float64) int {
+	return max(0, min(100, int(math.Round(float64(volume)*scale))))
+}
+
+// aggregateVolume maps the members' volumes back to a group volume: the
+// mean of each member's volume divided by its scale.
+func aggregateVolume(members []groupMemberState) int {
+	if len(members) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, m := range members {
+		sum += float64(m.Volume) / m.Scale
+	}
+	return max(0, min(100, int(math.Round(sum/float64(len(members))))))
+}
+
+// groupControlName returns the card's name for a member's control, which
+// may be given in full or as a base name like "Front".
+func (s *Server) groupControlName(member config.GroupMember) string {
+	if controls, err := s.mixer.ListControls(member.Card); err == nil {
+		if ctrl, ok := findControl(controls, member.Control); ok {
+			return ctrl.Name
+		}
+	}
+	return s.resolveVolumeControlName(member.Card, member.Control)
+}
+
+// readGroup reads the current volume of each member of the named group.
+func (s *Server) readGroup(name string, members []config.GroupMember) (groupState, error) {
+	state := groupState{Name: name, Members: make([]groupMemberState, 0, len(members))}
+	for _, member := range members {
+		control := s.groupControlName(member)
+		volumes, err := s.mixer.GetVolume(member.Card, control)
+		if err != nil {
+			return state, fmt.Errorf("failed to read %s on card %d: %w", control, member.Card, err)
+		}
+		ms := groupMemberState{Card: member.Card, Control: control, Scale: member.Scale}
+		if len(volumes) > 0 {
+			ms.Volume = volumes[0]
+		}
+		state.Members = append(state.Members, ms)
+	}
+	state.Volume = aggregateVolume(state.Members)
+	return state, nil
+}
+
+// GroupHandler serves GET /api/group/{name}: the group's aggregate volume
+// and its members' volumes.
+func (s *Server) GroupHandler(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	members, ok := s.config.Groups[name]
+	if !ok {
+		http.Error(w, "group not found", http.StatusNotFound)
+		return
+	}
+
+	state, err := s.readGroup(name, members)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(state)
+}
+
+// GroupVolumeHandler serves POST /api/group/{name}/volume. It sets every
+// member to the group volume times its scale, broadcasts each change and
+// responds with the group as GroupHandler does.
+func (s *Server) GroupVolumeHandler(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	members, ok := s.config.Groups[name]
+	if !ok {
+		http.Error(w, "group not found", http.StatusNotFound)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form data", http.StatusBadRequest)
+		return
+	}
+	volumeStr := r.Form.Get("value")
+	if volumeStr == "" {
+		volumeStr = r.Form.Get("volume")
+	}
+	if volumeStr == "" {
+		http.Error(w, "missing volume value", http.StatusBadRequest)
+		return
+	}
+	values, err := parseVolumes(volumeStr, s.config.StrictVolume)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid volume: %v", err), http.StatusBadRequest)
+		return
+	}
+	if len(values) != 1 {
+		http.Error(w, "a group volume is a single value", http.StatusBadRequest)
+		return
+	}
+	volume := values[0]
+
+	log.Printf("[POST /api/group/%s/volume] volume=%d", name, volume)
+
+	m := s.mixer
+	for _, member := range members {
+		control := s.groupControlName(member)
+		v := memberVolume(volume, member.Scale)
+		s.ramps.cancel(rampKey(member.Card, control))
+		if err := m.SetVolume(member.Card, control, []int{v}); err != nil {
+			http.Error(w, fmt.Sprintf("failed to set %s on card %d: %v", control, member.Card, err), http.StatusInternalServerError)
+			return
+		}
+		muted := false
+		if ctrl := s.getControlView(member.Card, control); ctrl != nil {
+			muted = ctrl.Muted
+		}
+		s.broadcastControl(member.Card, control, v, muted)
+	}
+
+	state, err := s.readGroup(name, members)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(state)
+}
@@ -0,0 +1,138 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/user/alsamixer-web/internal/alsa"
+	"github.com/user/alsamixer-web/internal/config"
+	"github.com/user/alsamixer-web/internal/sse"
+)
+
+// volumeMapMixer is a fakeMixer that keeps each control's volume.
+type volumeMapMixer struct {
+	*fakeMixer
+	mu      sync.Mutex
+	volumes map[string][]int
+}
+
+func (m *volumeMapMixer) GetVolume(card uint, control string) ([]int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.volumes[control], nil
+}
+
+func (m *volumeMapMixer) SetVolume(card uint, control string, values []int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.volumes[control] = append([]int(nil), values...)
+	return nil
+}
+
+func TestGroupVolume(t *testing.T) {
+	cfg := &config.Config{
+		Port:     0,
+		BindAddr: "127.0.0.1",
+		Groups: map[string][]config.GroupMember{
+			"speakers": {
+				{Card: 0, Control: "Front", Scale: 1},
+				{Card: 0, Control: "Surround Playback Volume", Scale: 0.5},
+			},
+		},
+	}
+	srv := newTestServer(t, cfg, sse.NewHub())
+	vm := &volumeMapMixer{
+		fakeMixer: &fakeMixer{controls: []alsa.Control{
+			{Name: "Front Playback Volume", Type: "integer", Min: 0, Max: 100, Step: 1, Count: 2},
+			{Name: "Surround Playback Volume", Type: "integer", Min: 0, Max: 100, Step: 1, Count: 2},
+		}},
+		volumes: map[string][]int{},
+	}
+	srv.useMixer(vm)
+
+	form := url.Values{"volume": {"80"}}
+	req := httptest.NewRequest(http.MethodPost, "/api/group/speakers/volume", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp := httptest.NewRecorder()
+	srv.mux.ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+	if got := vm.volumes["Front Playback Volume"]; len(got) != 1 || got[0] != 80 {
+		t.Errorf("expected Front at 80, got %v", got)
+	}
+	if got := vm.volumes["Surround Playback Volume"]; len(got) != 1 || got[0] != 40 {
+		t.Errorf("expected Surround scaled to 40, got %v", got)
+	}
+
+	// Move one member on its own: the group reports the mean of its
+	// members' unscaled volumes.
+	vm.volumes["Front Playback Volume"] = []int{60, 60}
+	req = httptest.NewRequest(http.MethodGet, "/api/group/speakers", nil)
+	resp = httptest.NewRecorder()
+	srv.mux.ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.Code)
+	}
+	var state groupState
+	if err := json.NewDecoder(resp.Body).Decode(&state); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if state.Volume != 70 {
+		t.Errorf("expected group volume 70, got %d", state.Volume)
+	}
+	if len(state.Members) != 2 || state.Members[0].Volume != 60 || state.Members[1].Volume != 40 {
+		t.Errorf("expected members at 60 and 40, got %+v", state.Members)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/group/nope", nil)
+	resp = httptest.NewRecorder()
+	srv.mux.ServeHTTP(resp, req)
+	if resp.Code != http.StatusNotFound {
+		t.Errorf("expected status %d for an unknown group, got %d", http.StatusNotFound, resp.Code)
+	}
+}
+
+func TestGroupWithHiddenMember(t *testing.T) {
+	cfg := &config.Config{
+		Port:        0,
+		BindAddr:    "127.0.0.1",
+		ExposeCards: []string{"0"},
+		Groups: map[string][]config.GroupMember{
+			"speakers": {
+				{Card: 0, Control: "Front", Scale: 1},
+				{Card: 1, Control: "Headphone", Scale: 1},
+			},
+		},
+	}
+	srv := newTestServer(t, cfg, sse.NewHub())
+	vm := &volumeMapMixer{
+		fakeMixer: &fakeMixer{cards: []alsa.Card{{ID: 0, Name: "PCH"}, {ID: 1, Name: "USB"}}},
+		volumes:   map[string][]int{},
+	}
+	srv.useMixer(vm)
+
+	form := url.Values{"volume": {"80"}}
+	req := httptest.NewRequest(http.MethodPost, "/api/group/speakers/volume", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp := httptest.NewRecorder()
+	srv.mux.ServeHTTP(resp, req)
+	if resp.Code != http.StatusNotFound {
+		t.Errorf("expected status %d for a group on a hidden card, got %d", http.StatusNotFound, resp.Code)
+	}
+	if len(vm.volumes) != 0 {
+		t.Errorf("expected no member to be set, got %v", vm.volumes)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/group/speakers", nil)
+	resp = httptest.NewRecorder()
+	srv.mux.ServeHTTP(resp, req)
+	if resp.Code != http.StatusNotFound {
+		t.Errorf("expected status %d reading a group on a hidden card, got %d", http.StatusNotFound, resp.Code)
+	}
+}
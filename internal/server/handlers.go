@@ -5,14 +5,45 @@ import (
 	"fmt"
 	"log"
 	"net/http"
-	"net/url"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/user/alsamixer-web/internal/alsa"
 	"github.com/user/alsamixer-web/internal/sse"
 )
 
+// findControl returns the control called name. An exact match wins;
+// otherwise case is ignored, so a hand-typed "master playback volume"
+// still finds "Master Playback Volume".
+func findControl(controls []alsa.Control, name string) (alsa.Control, bool) {
+	for _, ctrl := range controls {
+		if ctrl.Name == name {
+			return ctrl, true
+		}
+	}
+	for _, ctrl := range controls {
+		if strings.EqualFold(ctrl.Name, name) {
+			return ctrl, true
+		}
+	}
+	return alsa.Control{}, false
+}
+
+// canonicalControlName returns the card's own spelling of name, or name
+// unchanged if the card has no such control.
+func canonicalControlName(m mixer, cardID uint, name string) string {
+	controls, err := m.ListControls(cardID)
+	if err != nil {
+		return name
+	}
+	if ctrl, ok := findControl(controls, name); ok {
+		return ctrl.Name
+	}
+	return name
+}
+
 func (s *Server) resolveVolumeControlName(cardID uint, baseName string) string {
 	controls, err := s.mixer.ListControls(cardID)
 	if err != nil {
@@ -24,6 +55,13 @@ func (s *Server) resolveVolumeControlName(cardID uint, baseName string) string {
 			return ctrl.Name
 		}
 	}
+	// Hand-typed API calls often get the case wrong
+	for _, ctrl := range controls {
+		bn := extractBaseName(ctrl.Name)
+		if strings.EqualFold(bn, baseName) && strings.Contains(ctrl.Name, "Volume") {
+			return ctrl.Name
+		}
+	}
 	return baseName + " Playback Volume"
 }
 
@@ -32,16 +70,33 @@ func (s *Server) resolveSwitchControlName(cardID uint, baseName string) string {
 	return strings.Replace(volName, " Volume", " Switch", 1)
 }
 
+// controlNameFromRequest returns the control base name addressed by a
+// card-scoped request: the {controlName} path segment if the route has one,
+// otherwise the "control" query or form parameter. The routes without the
+// segment keep names the path cannot represent (e.g. containing "/", which
+// proxies commonly decode before it reaches us) addressable.
+func controlNameFromRequest(r *http.Request) (string, error) {
+	// PathValue is already unescaped; unescaping it again would break
+	// names containing "%".
+	if name := r.PathValue("controlName"); name != "" {
+		return name, nil
+	}
+	if err := r.ParseForm(); err != nil {
+		return "", fmt.Errorf("invalid form data")
+	}
+	if name := r.Form.Get("control"); name != "" {
+		return name, nil
+	}
+	return "", fmt.Errorf("missing control name")
+}
+
 func (s *Server) CardControlVolumeHandler(w http.ResponseWriter, r *http.Request) {
 	cardIDStr := r.PathValue("cardId")
-	controlBaseName := r.PathValue("controlName")
-
-	unescapedName, err := url.PathUnescape(controlBaseName)
+	controlBaseName, err := controlNameFromRequest(r)
 	if err != nil {
-		http.Error(w, "invalid control name", http.StatusBadRequest)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	controlBaseName = unescapedName
 
 	cardID, err := strconv.ParseUint(cardIDStr, 10, 0)
 	if err != nil {
@@ -63,72 +118,62 @@ func (s *Server) CardControlVolumeHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	volume, err := strconv.Atoi(volumeStr)
+	values, err := parseVolumes(volumeStr, s.config.StrictVolume)
 	if err != nil {
-		http.Error(w, "invalid volume", http.StatusBadRequest)
+		http.Error(w, fmt.Sprintf("invalid volume: %v", err), http.StatusBadRequest)
 		return
 	}
+	volume := values[0]
 
-	if volume < 0 {
-		volume = 0
-	} else if volume > 100 {
-		volume = 100
+	ramp, err := parseRamp(r.Form.Get("ramp"))
+	if err != nil {
+		http.Error(w, "invalid ramp", http.StatusBadRequest)
+		return
 	}
 
 	controlName := s.resolveVolumeControlName(uint(cardID), controlBaseName)
 
-	log.Printf("[POST /card/%d/control/%s/volume] volume=%d (resolved: %s)", cardID, controlBaseName, volume, controlName)
+	log.Printf("[POST /card/%d/control/%s/volume] volume=%v (resolved: %s)", cardID, controlBaseName, values, controlName)
 
-	m := newMixer()
-	if m == nil {
-		http.Error(w, "mixer unavailable", http.StatusInternalServerError)
-		return
-	}
-	if closer, ok := m.(interface{ Close() error }); ok {
-		defer closer.Close()
-	}
+	m := s.mixer
 
 	// Check if control exists before trying to set it
 	controls, err := m.ListControls(uint(cardID))
 	if err == nil {
-		found := false
-		for _, ctrl := range controls {
-			if ctrl.Name == controlName {
-				found = true
-				break
-			}
-		}
+		ctrl, found := findControl(controls, controlName)
 		if !found {
 			http.Error(w, "control not found", http.StatusBadRequest)
 			return
 		}
+		if err := checkChannelCount(ctrl, values); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		controlName = ctrl.Name
 	}
 
-	if err := m.SetVolume(uint(cardID), controlName, []int{volume}); err != nil {
+	if ramp > 0 {
+		if len(values) > 1 {
+			http.Error(w, "ramp needs a single volume", http.StatusBadRequest)
+			return
+		}
+		s.startVolumeRamp(uint(cardID), controlName, volume, ramp)
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	s.ramps.cancel(rampKey(uint(cardID), controlName))
+
+	if err := m.SetVolume(uint(cardID), controlName, values); err != nil {
 		http.Error(w, fmt.Sprintf("failed to set volume: %v", err), http.StatusInternalServerError)
 		return
 	}
 
+	volume = readBackVolume(m, uint(cardID), controlName, volume)
+
 	if s.hub != nil {
-		ctrl := s.getControlView(uint(cardID), controlName)
-		if ctrl != nil {
-			log.Printf("[SSE broadcast] %s", compactEventData(ctrl))
-			go s.hub.Broadcast(sse.Event{
-				Type: "mixer-update",
-				Data: map[string]interface{}{
-					"state": map[string]interface{}{
-						fmt.Sprintf("%d", cardID): map[string]interface{}{
-							controlName: map[string]interface{}{
-								"Volume": []int{volume},
-								"Mute":   ctrl.Muted,
-							},
-						},
-					},
-					"source":  "handler",
-					"control": controlName,
-				},
-			})
-		}
+		muted, _ := m.GetMute(uint(cardID), strings.Replace(controlName, " Volume", " Switch", 1))
+		log.Printf("[SSE broadcast] %s", compactEventData(&controlView{Name: controlName, VolumeNow: volume, Muted: muted}))
+		s.broadcastControl(uint(cardID), controlName, volume, muted)
 	}
 
 	w.WriteHeader(http.StatusNoContent)
@@ -136,14 +181,11 @@ func (s *Server) CardControlVolumeHandler(w http.ResponseWriter, r *http.Request
 
 func (s *Server) CardControlMuteHandler(w http.ResponseWriter, r *http.Request) {
 	cardIDStr := r.PathValue("cardId")
-	controlBaseName := r.PathValue("controlName")
-
-	unescapedName, err := url.PathUnescape(controlBaseName)
+	controlBaseName, err := controlNameFromRequest(r)
 	if err != nil {
-		http.Error(w, "invalid control name", http.StatusBadRequest)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	controlBaseName = unescapedName
 
 	cardID, err := strconv.ParseUint(cardIDStr, 10, 0)
 	if err != nil {
@@ -151,18 +193,15 @@ func (s *Server) CardControlMuteHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	m := newMixer()
-	if m == nil {
-		http.Error(w, "mixer unavailable", http.StatusInternalServerError)
-		return
-	}
-	if closer, ok := m.(interface{ Close() error }); ok {
-		defer closer.Close()
-	}
+	m := s.mixer
 
 	switchControl := s.resolveSwitchControlName(uint(cardID), controlBaseName)
 	volumeControl := s.resolveVolumeControlName(uint(cardID), controlBaseName)
 
+	if !checkSupports(w, m, uint(cardID), switchControl, alsa.OpMute) {
+		return
+	}
+
 	currentMuted, err := m.GetMute(uint(cardID), switchControl)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("failed to get mute state: %v", err), http.StatusInternalServerError)
@@ -182,21 +221,7 @@ func (s *Server) CardControlMuteHandler(w http.ResponseWriter, r *http.Request)
 		ctrl := s.getControlView(uint(cardID), volumeControl)
 		if ctrl != nil {
 			log.Printf("[SSE broadcast] %s", compactEventData(ctrl))
-			go s.hub.Broadcast(sse.Event{
-				Type: "mixer-update",
-				Data: map[string]interface{}{
-					"state": map[string]interface{}{
-						fmt.Sprintf("%d", cardID): map[string]interface{}{
-							volumeControl: map[string]interface{}{
-								"Volume": []int{ctrl.VolumeNow},
-								"Mute":   newMuted,
-							},
-						},
-					},
-					"source":  "handler",
-					"control": volumeControl,
-				},
-			})
+			s.broadcastControl(uint(cardID), volumeControl, ctrl.VolumeNow, newMuted)
 		}
 	}
 
@@ -210,14 +235,11 @@ func (s *Server) CardControlMuteHandler(w http.ResponseWriter, r *http.Request)
 
 func (s *Server) CardControlCaptureHandler(w http.ResponseWriter, r *http.Request) {
 	cardIDStr := r.PathValue("cardId")
-	controlBaseName := r.PathValue("controlName")
-
-	unescapedName, err := url.PathUnescape(controlBaseName)
+	controlBaseName, err := controlNameFromRequest(r)
 	if err != nil {
-		http.Error(w, "invalid control name", http.StatusBadRequest)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	controlBaseName = unescapedName
 
 	cardID, err := strconv.ParseUint(cardIDStr, 10, 0)
 	if err != nil {
@@ -225,18 +247,15 @@ func (s *Server) CardControlCaptureHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	m := newMixer()
-	if m == nil {
-		http.Error(w, "mixer unavailable", http.StatusInternalServerError)
-		return
-	}
-	if closer, ok := m.(interface{ Close() error }); ok {
-		defer closer.Close()
-	}
+	m := s.mixer
 
 	switchControl := s.resolveSwitchControlName(uint(cardID), controlBaseName)
 	volumeControl := s.resolveVolumeControlName(uint(cardID), controlBaseName)
 
+	if !checkSupports(w, m, uint(cardID), switchControl, alsa.OpCapture) {
+		return
+	}
+
 	currentMuted, err := m.GetMute(uint(cardID), switchControl)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("failed to get capture state: %v", err), http.StatusInternalServerError)
@@ -257,21 +276,7 @@ func (s *Server) CardControlCaptureHandler(w http.ResponseWriter, r *http.Reques
 		ctrl := s.getControlView(uint(cardID), volumeControl)
 		if ctrl != nil {
 			log.Printf("[SSE broadcast] %s", compactEventData(ctrl))
-			go s.hub.Broadcast(sse.Event{
-				Type: "mixer-update",
-				Data: map[string]interface{}{
-					"state": map[string]interface{}{
-						fmt.Sprintf("%d", cardID): map[string]interface{}{
-							volumeControl: map[string]interface{}{
-								"Volume": []int{ctrl.VolumeNow},
-								"Mute":   newMuted,
-							},
-						},
-					},
-					"source":  "handler",
-					"control": volumeControl,
-				},
-			})
+			s.broadcastControl(uint(cardID), volumeControl, ctrl.VolumeNow, newMuted)
 		}
 	}
 
@@ -283,6 +288,187 @@ func (s *Server) CardControlCaptureHandler(w http.ResponseWriter, r *http.Reques
 	})
 }
 
+// broadcastDedupe remembers the last handler broadcast of each control, so
+// that repeating the same value straight away is not sent again.
+type broadcastDedupe struct {
+	mu   sync.Mutex
+	last map[string]dedupeEntry
+}
+
+type dedupeEntry struct {
+	volume int
+	muted  bool
+	at     time.Time
+}
+
+// duplicate reports whether volume and muted repeat the control's previous
+// broadcast within window. Otherwise it records them as the latest.
+func (d *broadcastDedupe) duplicate(cardID uint, control string, volume int, muted bool, window time.Duration, now time.Time) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	key := rampKey(cardID, control)
+	if prev, ok := d.last[key]; ok && prev.volume == volume && prev.muted == muted && now.Sub(prev.at) < window {
+		return true
+	}
+	if d.last == nil {
+		d.last = make(map[string]dedupeEntry)
+	}
+	d.last[key] = dedupeEntry{volume: volume, muted: muted, at: now}
+	return false
+}
+
+// forgetSnapshot drops the previous broadcast of every control in delta.
+// Once the monitor has seen a control change, a handler setting it back to
+// the value last broadcast is news to clients again.
+func (d *broadcastDedupe) forgetSnapshot(delta *alsa.StateSnapshot) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for cardID, card := range delta.Cards {
+		for control := range card.Controls {
+			delete(d.last, rampKey(cardID, control))
+		}
+	}
+}
+
+// checkSupports answers 400 and returns false if control on card cannot
+// perform op, rather than letting the operation fail with a 500 halfway.
+// When the capabilities cannot be read the operation is attempted anyway.
+func checkSupports(w http.ResponseWriter, m mixer, cardID uint, control string, op alsa.Operation) bool {
+	ok, err := m.Supports(cardID, control, op)
+	if err != nil || ok {
+		return true
+	}
+	http.Error(w, fmt.Sprintf("control %s does not support %s", control, op), http.StatusBadRequest)
+	return false
+}
+
+// suspendMonitor holds back monitor broadcasts for the duration of a bulk
+// operation; call the returned function when it is done.
+func (s *Server) suspendMonitor() (resume func()) {
+	if s.monitor == nil {
+		return func() {}
+	}
+	return s.monitor.Suspend()
+}
+
+// broadcastControl tells all clients about a handler-originated change to a
+// control. The timestamp (unix millis) lets clients order handler echoes
+// against monitor updates and keep the newest. With --dedupe-window, a
+// broadcast identical to the control's previous one is dropped.
+func (s *Server) broadcastControl(cardID uint, control string, volume int, muted bool) {
+	s.broadcastControlDB(cardID, control, volume, muted, nil)
+}
+
+// broadcastControlDB is broadcastControl that also reports the control's
+// level in dB, if db is not nil.
+func (s *Server) broadcastControlDB(cardID uint, control string, volume int, muted bool, db []float64) {
+	s.history.record(cardID, control, []int{volume}, time.Now())
+	s.publishControl(cardID, control, volume, muted, db)
+}
+
+// publishControl is broadcastControlDB without recording the volume in the
+// control's history, for intermediate ramp steps.
+func (s *Server) publishControl(cardID uint, control string, volume int, muted bool, db []float64) {
+	if s.monitor != nil {
+		// Keep the monitor from echoing the hardware's rounded value back
+		// while the user is still moving the control.
+		s.monitor.NoteLocalChange(cardID, control)
+	}
+	if s.hub == nil {
+		return
+	}
+	if window := s.config.DedupeWindow; window > 0 && s.dedupe.duplicate(cardID, control, volume, muted, window, time.Now()) {
+		return
+	}
+	state := map[string]interface{}{
+		"Volume": []int{volume},
+		"Mute":   muted,
+	}
+	if db != nil {
+		state["VolumeDB"] = db
+	}
+	go s.hub.Broadcast(sse.Event{
+		Type: "mixer-update",
+		Data: map[string]interface{}{
+			"state": map[string]interface{}{
+				fmt.Sprintf("%d", cardID): map[string]interface{}{
+					control: state,
+				},
+			},
+			"source":    "handler",
+			"control":   control,
+			"timestamp": time.Now().UnixMilli(),
+		},
+	})
+}
+
+// volumeReadBackTolerance is how far, in percent, the volume read back after
+// a set may differ from the request before we treat the request as not
+// having taken. Converting percent to raw steps and back is often off by one.
+const volumeReadBackTolerance = 1
+
+// readBackVolume returns the volume control actually settled on after it was
+// set to requested. If the read-back fails the request is assumed to have
+// taken as-is.
+func readBackVolume(m mixer, cardID uint, control string, requested int) int {
+	volumes, err := m.GetVolume(cardID, control)
+	if err != nil || len(volumes) == 0 {
+		return requested
+	}
+
+	actual := volumes[0]
+	if diff := actual - requested; diff > volumeReadBackTolerance || diff < -volumeReadBackTolerance {
+		log.Printf("SetVolume: %s on card %d reads back %d%% after setting %d%%; reporting actual value", control, cardID, actual, requested)
+		return actual
+	}
+	return requested
+}
+
+// parseVolumes parses a volume parameter: either one percentage for every
+// channel or a comma-separated percentage per channel. Values outside 0-100
+// are clamped, or rejected if strict.
+func parseVolumes(raw string, strict bool) ([]int, error) {
+	parts := strings.Split(raw, ",")
+	values := make([]int, 0, len(parts))
+	for _, part := range parts {
+		v, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	if strict {
+		if err := checkVolumeRange(values); err != nil {
+			return nil, err
+		}
+	}
+	for i, v := range values {
+		values[i] = max(0, min(100, v))
+	}
+	return values, nil
+}
+
+// checkVolumeRange rejects percentages outside 0-100, for --strict-volume.
+func checkVolumeRange(values []int) error {
+	for _, v := range values {
+		if v < 0 || v > 100 {
+			return fmt.Errorf("volume %d out of range 0-100", v)
+		}
+	}
+	return nil
+}
+
+// checkChannelCount rejects per-channel volumes that don't match ctrl's
+// channel count. A single value is always fine; it applies to every channel.
+func checkChannelCount(ctrl alsa.Control, values []int) error {
+	if len(values) == 1 || ctrl.Count == 0 || len(values) == ctrl.Count {
+		return nil
+	}
+	return fmt.Errorf("%s has %d channels, got %d volumes", ctrl.Name, ctrl.Count, len(values))
+}
+
 // compactEventData creates a compact JSON representation of an SSE broadcast for logging
 func compactEventData(ctrl *controlView) string {
 	if ctrl == nil {
@@ -303,21 +489,37 @@ func compactEventData(ctrl *controlView) string {
 	return string(b)
 }
 
-// mixer defines the subset of the ALSA mixer interface used by
-// the HTTP control handlers. It is defined here so tests can
-// swap in a fake implementation without requiring real ALSA
-// hardware.
+// mixer is everything the server needs from ALSA. *alsa.Mixer implements
+// it; the server and handlers only ever hold this interface so tests can
+// swap in a fake and decorators (such as timedMixer) can wrap the real one
+// without requiring real ALSA hardware.
+//
+// The server holds a single mixer, Server.mixer, which the page, every
+// handler and the monitor all read and write through, so they never see
+// different states. Implementations must therefore be safe for concurrent
+// use; *alsa.Mixer serializes its calls. A handler's read-modify-write
+// sequence is not atomic: two concurrent requests for one control may
+// interleave, and the last write wins.
 type mixer interface {
+	ListCards() ([]alsa.Card, error)
+	ListControls(card uint) ([]alsa.Control, error)
+	GetVolume(card uint, control string) ([]int, error)
+	SetVolume(card uint, control string, values []int) error
+	GetRawVolume(card uint, control string) ([]int, error)
+	SetRawVolume(card uint, control string, values []int) error
+	GetVolumeDB(card uint, control string) ([]float64, error)
+	SetVolumeDB(card uint, control string, values []float64) error
+	VolumeDBRange(card uint, control string) (min, max float64, err error)
 	GetMute(card uint, control string) (bool, error)
 	SetMute(card uint, control string, muted bool) error
-	SetVolume(card uint, control string, values []int) error
-	ListControls(card uint) ([]alsa.Control, error)
-}
-
-// newMixer constructs a real ALSA mixer. Tests may override this
-// variable with a stub implementation.
-var newMixer = func() mixer {
-	return alsa.NewMixer()
+	HasPlaybackVolume(card uint, control string) (bool, error)
+	HasPlaybackSwitch(card uint, control string) (bool, error)
+	HasCaptureVolume(card uint, control string) (bool, error)
+	HasCaptureSwitch(card uint, control string) (bool, error)
+	Supports(card uint, control string, op alsa.Operation) (bool, error)
+	IsOpen() bool
+	UnavailableReason() string
+	Close() error
 }
 
 // MuteHandler handles POST /control/mute requests from HTMX
@@ -361,17 +563,15 @@ func (s *Server) MuteHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	m := newMixer()
-	if m == nil {
-		http.Error(w, "mixer unavailable", http.StatusInternalServerError)
-		return
-	}
-	if closer, ok := m.(interface{ Close() error }); ok {
-		defer closer.Close()
-	}
+	m := s.mixer
+
+	control = canonicalControlName(m, cardID, control)
 
 	// Use the corresponding switch control for mute
 	switchControl := strings.Replace(control, " Volume", " Switch", 1)
+	if !checkSupports(w, m, cardID, switchControl, alsa.OpMute) {
+		return
+	}
 	currentMuted, err := m.GetMute(cardID, switchControl)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("failed to get mute state: %v", err), http.StatusInternalServerError)
@@ -391,21 +591,7 @@ func (s *Server) MuteHandler(w http.ResponseWriter, r *http.Request) {
 			// Log the SSE broadcast (compact JSON)
 			log.Printf("[SSE broadcast] %s", compactEventData(ctrl))
 			// Broadcast mixer-update style event for JS-only clients
-			go s.hub.Broadcast(sse.Event{
-				Type: "mixer-update",
-				Data: map[string]interface{}{
-					"state": map[string]interface{}{
-						fmt.Sprintf("%d", cardID): map[string]interface{}{
-							control: map[string]interface{}{
-								"Volume": []int{ctrl.VolumeNow},
-								"Mute":   newMuted,
-							},
-						},
-					},
-					"source":  "handler",
-					"control": control,
-				},
-			})
+			s.broadcastControl(cardID, control, ctrl.VolumeNow, newMuted)
 		}
 	}
 
@@ -452,78 +638,168 @@ func (s *Server) VolumeHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	cardID := uint(cardValue)
 
-	volume, err := strconv.Atoi(volumeStr)
+	// One volume for every channel, or a comma-separated one per channel;
+	// each is clamped to the 0-100 range unless --strict-volume
+	values, err := parseVolumes(volumeStr, s.config.StrictVolume)
 	if err != nil {
-		http.Error(w, "invalid volume", http.StatusBadRequest)
+		http.Error(w, fmt.Sprintf("invalid volume: %v", err), http.StatusBadRequest)
 		return
 	}
+	volume := values[0]
 
-	// Clamp volume to 0-100 range
-	if volume < 0 {
-		volume = 0
-	} else if volume > 100 {
-		volume = 100
-	}
-
-	m := newMixer()
-	if m == nil {
-		http.Error(w, "mixer unavailable", http.StatusInternalServerError)
+	// Optional fade duration in milliseconds; default is instantaneous
+	ramp, err := parseRamp(r.Form.Get("ramp"))
+	if err != nil {
+		http.Error(w, "invalid ramp", http.StatusBadRequest)
 		return
 	}
-	if closer, ok := m.(interface{ Close() error }); ok {
-		defer closer.Close()
-	}
 
-	// Validate control exists before trying to set it
+	m := s.mixer
+
+	// Validate control exists before trying to set it, and use its
+	// canonical name from here on
 	controls, err := m.ListControls(cardID)
 	if err == nil {
-		found := false
-		for _, ctrl := range controls {
-			if ctrl.Name == control {
-				found = true
-				break
-			}
-		}
+		ctrl, found := findControl(controls, control)
 		if !found {
 			http.Error(w, "control not found", http.StatusBadRequest)
 			return
 		}
+		if err := checkChannelCount(ctrl, values); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		control = ctrl.Name
 	}
 
-	if err := m.SetVolume(cardID, control, []int{volume}); err != nil {
+	if ramp > 0 {
+		if len(values) > 1 {
+			http.Error(w, "ramp needs a single volume", http.StatusBadRequest)
+			return
+		}
+		s.startVolumeRamp(cardID, control, volume, ramp)
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	// An instant set supersedes any fade still running for this control
+	s.ramps.cancel(rampKey(cardID, control))
+
+	if err := m.SetVolume(cardID, control, values); err != nil {
 		http.Error(w, fmt.Sprintf("failed to set volume: %v", err), http.StatusInternalServerError)
 		return
 	}
 
 	// Broadcast SSE event so other clients stay in sync.
+	// amixer can exit 0 while clamping or ignoring the value, so tell
+	// clients what the hardware actually took rather than what was asked.
+	volume = readBackVolume(m, cardID, control, volume)
+
 	if s.hub != nil {
-		ctrl := s.getControlView(cardID, control)
-		if ctrl != nil {
-			// Log the SSE broadcast (compact JSON)
-			log.Printf("[SSE broadcast] %s", compactEventData(ctrl))
-			// Broadcast mixer-update style event for JS-only clients
-			// Include timestamp so client knows this is fresh from handler (not monitor)
-			go s.hub.Broadcast(sse.Event{
-				Type: "mixer-update",
-				Data: map[string]interface{}{
-					"state": map[string]interface{}{
-						fmt.Sprintf("%d", cardID): map[string]interface{}{
-							control: map[string]interface{}{
-								"Volume": []int{volume},
-								"Mute":   ctrl.Muted,
-							},
-						},
-					},
-					"source":  "handler",
-					"control": control,
-				},
-			})
-		}
+		muted, _ := m.GetMute(cardID, strings.Replace(control, " Volume", " Switch", 1))
+		// Log the SSE broadcast (compact JSON)
+		log.Printf("[SSE broadcast] %s", compactEventData(&controlView{Name: control, VolumeNow: volume, Muted: muted}))
+		// Broadcast mixer-update style event for JS-only clients
+		s.broadcastControl(cardID, control, volume, muted)
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// VolumeStepHandler handles POST /control/volume/step requests. It moves
+// every channel of a control by one raw hardware unit in the given
+// direction ("up" or "down"), like alsamixer's arrow keys, which is finer
+// than a percentage on controls with many steps.
+func (s *Server) VolumeStepHandler(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	cardStr := r.Form.Get("card")
+	control := r.Form.Get("control")
+	direction := r.Form.Get("direction")
+	if cardStr == "" || control == "" {
+		http.Error(w, "missing card or control", http.StatusBadRequest)
+		return
+	}
+
+	cardValue, err := strconv.ParseUint(cardStr, 10, 0)
+	if err != nil {
+		http.Error(w, "invalid card", http.StatusBadRequest)
+		return
+	}
+	cardID := uint(cardValue)
+
+	var delta int
+	switch direction {
+	case "up":
+		delta = 1
+	case "down":
+		delta = -1
+	default:
+		http.Error(w, "direction must be up or down", http.StatusBadRequest)
+		return
+	}
+
+	m := s.mixer
+
+	controls, err := m.ListControls(cardID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to list controls: %v", err), http.StatusInternalServerError)
+		return
+	}
+	ctrl, found := findControl(controls, control)
+	if !found {
+		http.Error(w, "control not found", http.StatusBadRequest)
+		return
+	}
+	if ctrl.Type != "integer" {
+		http.Error(w, "control has no volume", http.StatusBadRequest)
+		return
+	}
+
+	current, err := m.GetRawVolume(cardID, ctrl.Name)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to get volume: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	raw := make([]int, len(current))
+	for i, v := range current {
+		raw[i] = int(max(ctrl.Min, min(ctrl.Max, int64(v+delta))))
+	}
+
+	s.ramps.cancel(rampKey(cardID, ctrl.Name))
+	if err := m.SetRawVolume(cardID, ctrl.Name, raw); err != nil {
+		http.Error(w, fmt.Sprintf("failed to set volume: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if actual, err := m.GetRawVolume(cardID, ctrl.Name); err == nil && len(actual) > 0 {
+		raw = actual
+	}
+
+	log.Printf("[POST /control/volume/step] card=%d control=%s direction=%s raw=%v", cardID, ctrl.Name, direction, raw)
+
+	// Read the percentage back rather than converting raw, so it is on the
+	// card's trimmed scale like every other volume the UI shows.
+	volume := 0
+	if volumes, err := m.GetVolume(cardID, ctrl.Name); err == nil && len(volumes) > 0 {
+		volume = volumes[0]
+	}
+	if s.hub != nil {
+		muted, _ := m.GetMute(cardID, strings.Replace(ctrl.Name, " Volume", " Switch", 1))
+		s.broadcastControl(cardID, ctrl.Name, volume, muted)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"card":    cardID,
+		"control": ctrl.Name,
+		"raw":     raw,
+		"volume":  volume,
+	})
+}
+
 // CaptureHandler handles POST /control/capture requests from HTMX
 // toggle buttons. Capture "active" is treated as the inverse of
 // the underlying mute state.
@@ -562,18 +838,16 @@ func (s *Server) CaptureHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	m := newMixer()
-	if m == nil {
-		http.Error(w, "mixer unavailable", http.StatusInternalServerError)
-		return
-	}
-	if closer, ok := m.(interface{ Close() error }); ok {
-		defer closer.Close()
-	}
+	m := s.mixer
+
+	control = canonicalControlName(m, cardID, control)
 
 	// Capture "active" is modelled as not muted.
 	// Use the corresponding switch control
 	switchControl := strings.Replace(control, " Volume", " Switch", 1)
+	if !checkSupports(w, m, cardID, switchControl, alsa.OpCapture) {
+		return
+	}
 	currentMuted, err := m.GetMute(cardID, switchControl)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("failed to get capture state: %v", err), http.StatusInternalServerError)
@@ -595,21 +869,7 @@ func (s *Server) CaptureHandler(w http.ResponseWriter, r *http.Request) {
 			// Log the SSE broadcast (compact JSON)
 			log.Printf("[SSE broadcast] %s", compactEventData(ctrl))
 			// Broadcast mixer-update style event for JS-only clients
-			go s.hub.Broadcast(sse.Event{
-				Type: "mixer-update",
-				Data: map[string]interface{}{
-					"state": map[string]interface{}{
-						fmt.Sprintf("%d", cardID): map[string]interface{}{
-							control: map[string]interface{}{
-								"Volume": []int{ctrl.VolumeNow},
-								"Mute":   newMuted, // Capture is inverse of mute
-							},
-						},
-					},
-					"source":  "handler",
-					"control": control,
-				},
-			})
+			s.broadcastControl(cardID, control, ctrl.VolumeNow, newMuted)
 		}
 	}
 
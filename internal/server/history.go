@@ -0,0 +1,139 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/user/alsamixer-web/internal/alsa"
+)
+
+const (
+	// historySize is how many recent volumes are kept per control.
+	historySize = 32
+	// maxHistoryControls bounds how many controls have a history, so a
+	// card with hundreds of controls can't grow it without limit. Beyond
+	// it the least recently updated control's history is dropped.
+	maxHistoryControls = 256
+)
+
+// historyEntry is one recorded volume of a control.
+type historyEntry struct {
+	Volume []int     `json:"volume"`
+	At     time.Time `json:"at"`
+}
+
+// historyRing holds a control's last historySize volumes, overwriting the
+// oldest once full.
+type historyRing struct {
+	entries [historySize]historyEntry
+	next    int // index the next entry is written to
+	size    int
+}
+
+// newest returns the most recent entry; the ring must not be empty.
+func (r *historyRing) newest() historyEntry {
+	return r.entries[(r.next+historySize-1)%historySize]
+}
+
+// controlHistory keeps the recent volumes of each control, as changed
+// through the API or seen by the monitor, for sparklines in the UI.
+type controlHistory struct {
+	mu    sync.Mutex
+	rings map[string]*historyRing
+}
+
+// record appends volume to the control's history unless it repeats the
+// newest entry, e.g. the monitor reading back what a handler just set.
+func (h *controlHistory) record(cardID uint, control string, volume []int, now time.Time) {
+	if len(volume) == 0 {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	key := rampKey(cardID, control)
+	ring, ok := h.rings[key]
+	if !ok {
+		if h.rings == nil {
+			h.rings = make(map[string]*historyRing)
+		}
+		if len(h.rings) >= maxHistoryControls {
+			h.evictOldest()
+		}
+		ring = &historyRing{}
+		h.rings[key] = ring
+	}
+	if ring.size > 0 && slices.Equal(ring.newest().Volume, volume) {
+		return
+	}
+	ring.entries[ring.next] = historyEntry{Volume: slices.Clone(volume), At: now}
+	ring.next = (ring.next + 1) % historySize
+	ring.size = min(ring.size+1, historySize)
+}
+
+// evictOldest drops the history of the control updated least recently.
+// Must be called with h.mu held.
+func (h *controlHistory) evictOldest() {
+	var oldestKey string
+	var oldest time.Time
+	for key, ring := range h.rings {
+		if at := ring.newest().At; oldestKey == "" || at.Before(oldest) {
+			oldestKey, oldest = key, at
+		}
+	}
+	delete(h.rings, oldestKey)
+}
+
+// recordSnapshot records every volume in a monitor delta.
+func (h *controlHistory) recordSnapshot(delta *alsa.StateSnapshot) {
+	now := time.Now()
+	for cardID, card := range delta.Cards {
+		for control, state := range card.Controls {
+			h.record(cardID, control, state.Volume, now)
+		}
+	}
+}
+
+// get returns the control's recorded volumes, newest first.
+func (h *controlHistory) get(cardID uint, control string) []historyEntry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	entries := []historyEntry{}
+	ring, ok := h.rings[rampKey(cardID, control)]
+	if !ok {
+		return entries
+	}
+	for i := 1; i <= ring.size; i++ {
+		entries = append(entries, ring.entries[(ring.next+historySize-i)%historySize])
+	}
+	return entries
+}
+
+// HistoryHandler serves GET /api/card/{cardId}/control/{controlName}/history:
+// the control's last few volumes, newest first.
+func (s *Server) HistoryHandler(w http.ResponseWriter, r *http.Request) {
+	controlBaseName, err := controlNameFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	cardID, err := strconv.ParseUint(r.PathValue("cardId"), 10, 0)
+	if err != nil {
+		http.Error(w, "invalid card id", http.StatusBadRequest)
+		return
+	}
+
+	control := s.resolveVolumeControlName(uint(cardID), controlBaseName)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"card":    cardID,
+		"control": control,
+		"history": s.history.get(uint(cardID), control),
+	})
+}
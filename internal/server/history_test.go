@@ -0,0 +1,97 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/user/alsamixer-web/internal/alsa"
+	"github.com/user/alsamixer-web/internal/config"
+)
+
+func TestHistoryHandler(t *testing.T) {
+	cfg := &config.Config{
+		Port:     0,
+		BindAddr: "127.0.0.1",
+	}
+	srv := newTestServer(t, cfg, nil)
+	srv.useMixer(&fakeMixer{})
+
+	// Handler changes, with a repeat that must not be recorded twice,
+	// followed by what the monitor saw.
+	pushed := 0
+	for v := 0; v < historySize+4; v++ {
+		srv.broadcastControl(0, "Master Playback Volume", v, false)
+		srv.broadcastControl(0, "Master Playback Volume", v, false)
+		pushed = v
+	}
+	srv.history.recordSnapshot(&alsa.StateSnapshot{Cards: map[uint]alsa.CardState{
+		0: {Controls: map[string]alsa.ControlState{
+			"Master Playback Volume": {Volume: []int{90, 91}},
+			"Master Playback Switch": {Mute: true},
+		}},
+	}})
+	srv.broadcastControl(0, "PCM Playback Volume", 10, false)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/card/0/control/Master/history", nil)
+	resp := httptest.NewRecorder()
+	srv.mux.ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+
+	var body struct {
+		Control string         `json:"control"`
+		History []historyEntry `json:"history"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if body.Control != "Master Playback Volume" {
+		t.Errorf("expected control %q, got %q", "Master Playback Volume", body.Control)
+	}
+	if len(body.History) != historySize {
+		t.Fatalf("expected history bounded to %d entries, got %d", historySize, len(body.History))
+	}
+	if got := body.History[0].Volume; len(got) != 2 || got[0] != 90 || got[1] != 91 {
+		t.Errorf("expected the monitor's reading newest, got %v", got)
+	}
+	for i, entry := range body.History[1:] {
+		want := pushed - i
+		if len(entry.Volume) != 1 || entry.Volume[0] != want {
+			t.Fatalf("entry %d: expected volume [%d], got %v", i+1, want, entry.Volume)
+		}
+		if entry.At.After(body.History[i].At) {
+			t.Errorf("entry %d is newer than entry %d", i+1, i)
+		}
+	}
+}
+
+func TestHistoryEvictsLeastRecentlyUpdated(t *testing.T) {
+	var h controlHistory
+	start := time.Now()
+	for i := 0; i < maxHistoryControls; i++ {
+		h.record(0, fmt.Sprintf("Control %d", i), []int{50}, start.Add(time.Duration(i)*time.Second))
+	}
+	// Control 0 is updated again, leaving Control 1 the stalest.
+	h.record(0, "Control 0", []int{60}, start.Add(time.Hour))
+
+	// A control on a card plugged in later still gets a history.
+	h.record(1, "Headset", []int{30}, start.Add(2*time.Hour))
+
+	if got := h.get(1, "Headset"); len(got) != 1 {
+		t.Errorf("expected the new control to be recorded, got %v", got)
+	}
+	if got := h.get(0, "Control 1"); len(got) != 0 {
+		t.Errorf("expected the least recently updated control to be evicted, got %v", got)
+	}
+	if got := h.get(0, "Control 0"); len(got) != 2 {
+		t.Errorf("expected the recently updated control to be kept, got %v", got)
+	}
+	if len(h.rings) != maxHistoryControls {
+		t.Errorf("expected %d controls with a history, got %d", maxHistoryControls, len(h.rings))
+	}
+}
@@ -0,0 +1,127 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/user/alsamixer-web/internal/alsa"
+)
+
+// latencySamples is how many recent calls per card and operation are kept
+// for the average and p99 reported by /debug/monitor.
+const latencySamples = 256
+
+type latencyKey struct {
+	card uint
+	op   string
+}
+
+// latencyRing holds the most recent samples for one card and operation.
+type latencyRing struct {
+	samples []time.Duration
+	next    int
+}
+
+// latencyStats records how long mixer reads take, per card, so slow
+// hardware shows up without a profiler.
+type latencyStats struct {
+	mu    sync.Mutex
+	rings map[latencyKey]*latencyRing
+}
+
+func newLatencyStats() *latencyStats {
+	return &latencyStats{rings: make(map[latencyKey]*latencyRing)}
+}
+
+// observe records one call of op on card, overwriting the oldest sample
+// once latencySamples have been collected.
+func (l *latencyStats) observe(card uint, op string, d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	key := latencyKey{card: card, op: op}
+	ring, ok := l.rings[key]
+	if !ok {
+		ring = &latencyRing{}
+		l.rings[key] = ring
+	}
+	if len(ring.samples) < latencySamples {
+		ring.samples = append(ring.samples, d)
+		return
+	}
+	ring.samples[ring.next] = d
+	ring.next = (ring.next + 1) % latencySamples
+}
+
+// latencySummary is the JSON form of one card and operation's samples.
+type latencySummary struct {
+	Samples int     `json:"samples"`
+	AvgMs   float64 `json:"avg_ms"`
+	P99Ms   float64 `json:"p99_ms"`
+}
+
+// snapshot summarises the recorded samples, keyed by card ID then operation.
+func (l *latencyStats) snapshot() map[string]map[string]latencySummary {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	result := make(map[string]map[string]latencySummary)
+	for key, ring := range l.rings {
+		sorted := append([]time.Duration(nil), ring.samples...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+		var total time.Duration
+		for _, d := range sorted {
+			total += d
+		}
+		p99 := sorted[(len(sorted)*99+99)/100-1]
+
+		card := strconv.FormatUint(uint64(key.card), 10)
+		if result[card] == nil {
+			result[card] = make(map[string]latencySummary)
+		}
+		result[card][key.op] = latencySummary{
+			Samples: len(sorted),
+			AvgMs:   durationMs(total / time.Duration(len(sorted))),
+			P99Ms:   durationMs(p99),
+		}
+	}
+	return result
+}
+
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// timedMixer wraps a mixer and records the latency of its reads.
+type timedMixer struct {
+	mixer
+	stats *latencyStats
+}
+
+func (t timedMixer) ListControls(card uint) ([]alsa.Control, error) {
+	start := time.Now()
+	controls, err := t.mixer.ListControls(card)
+	t.stats.observe(card, "ListControls", time.Since(start))
+	return controls, err
+}
+
+func (t timedMixer) GetVolume(card uint, control string) ([]int, error) {
+	start := time.Now()
+	volumes, err := t.mixer.GetVolume(card, control)
+	t.stats.observe(card, "GetVolume", time.Since(start))
+	return volumes, err
+}
+
+// DebugMonitorHandler serves GET /debug/monitor with per-card read
+// latencies from both the monitor's polling and the HTTP handlers.
+func (s *Server) DebugMonitorHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"latency": s.latency.snapshot(),
+	})
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/user/alsamixer-web/internal/config"
+	"github.com/user/alsamixer-web/internal/sse"
+)
+
+func TestDebugMonitorReportsReadLatency(t *testing.T) {
+	cfg := &config.Config{
+		Port:     0,
+		BindAddr: "127.0.0.1",
+	}
+	srv := newTestServer(t, cfg, sse.NewHub())
+
+	fm := &fakeMixer{delay: 20 * time.Millisecond}
+	srv.useMixer(fm)
+
+	// The volume handler lists controls, sets the volume and reads it back.
+	resp := postVolume(srv, "50", "")
+	if resp.Code != http.StatusNoContent {
+		t.Fatalf("expected status %d, got %d", http.StatusNoContent, resp.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/monitor", nil)
+	resp = httptest.NewRecorder()
+	srv.mux.ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.Code)
+	}
+
+	var body struct {
+		Latency map[string]map[string]latencySummary `json:"latency"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	for _, op := range []string{"ListControls", "GetVolume"} {
+		got, ok := body.Latency["0"][op]
+		if !ok {
+			t.Fatalf("expected %s latency for card 0, got %v", op, body.Latency)
+		}
+		if got.Samples < 1 {
+			t.Errorf("expected at least one %s sample, got %d", op, got.Samples)
+		}
+		if got.AvgMs < 20 || got.P99Ms < 20 {
+			t.Errorf("expected %s latency of at least 20ms, got avg %.1fms p99 %.1fms", op, got.AvgMs, got.P99Ms)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// logBacklog is how many recent log lines a new /debug/logs subscriber
+// receives before live output.
+const logBacklog = 200
+
+// logBroadcaster is an io.Writer that keeps the most recent log lines and
+// fans each new line out to subscribers. The binary installs it alongside
+// the normal log output when --debug-logs is set; see LogWriter.
+type logBroadcaster struct {
+	mu      sync.Mutex
+	lines   []string
+	partial []byte
+	subs    map[chan string]struct{}
+}
+
+func newLogBroadcaster() *logBroadcaster {
+	return &logBroadcaster{subs: make(map[chan string]struct{})}
+}
+
+// Write splits p into lines and publishes each complete one. It never
+// blocks on slow subscribers; they miss lines instead.
+func (b *logBroadcaster) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.partial = append(b.partial, p...)
+	for {
+		i := bytes.IndexByte(b.partial, '\n')
+		if i < 0 {
+			break
+		}
+		line := string(b.partial[:i])
+		b.partial = b.partial[i+1:]
+
+		b.lines = append(b.lines, line)
+		if len(b.lines) > logBacklog {
+			b.lines = append([]string(nil), b.lines[len(b.lines)-logBacklog:]...)
+		}
+		for ch := range b.subs {
+			select {
+			case ch <- line:
+			default:
+			}
+		}
+	}
+	return len(p), nil
+}
+
+// subscribe returns the current backlog and a channel receiving new lines.
+func (b *logBroadcaster) subscribe() ([]string, chan string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan string, 64)
+	b.subs[ch] = struct{}{}
+	return append([]string(nil), b.lines...), ch
+}
+
+func (b *logBroadcaster) unsubscribe(ch chan string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.subs, ch)
+}
+
+// LogWriter returns the writer feeding /debug/logs, or nil unless
+// --debug-logs is set. The caller adds it to the log output; NewServer
+// leaves the global logger alone.
+func (s *Server) LogWriter() io.Writer {
+	if s.logs == nil {
+		return nil
+	}
+	return s.logs
+}
+
+// DebugLogsHandler serves GET /debug/logs. EventSource requests get a
+// stream of the application log, starting with the recent backlog; other
+// requests get a small page that subscribes to it.
+func (s *Server) DebugLogsHandler(w http.ResponseWriter, r *http.Request) {
+	if !strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(debugLogsPage))
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	backlog, lines := s.logs.subscribe()
+	defer s.logs.unsubscribe(lines)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	for _, line := range backlog {
+		fmt.Fprintf(w, "data: %s\n\n", line)
+	}
+	flusher.Flush()
+
+	for {
+		select {
+		case line := <-lines:
+			fmt.Fprintf(w, "data: %s\n\n", line)
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+const debugLogsPage = `<!doctype html>
+<html lang="en">
+  <head>
+    <meta charset="utf-8">
+    <title>alsamixer-web logs</title>
+    <style>
+      body { margin: 0; background: #000; color: #e6efe1; font: 13px/1.4 monospace; }
+      pre { margin: 0; padding: 1em; white-space: pre-wrap; }
+    </style>
+  </head>
+  <body>
+    <pre id="log"></pre>
+    <script>
+      var log = document.getElementById('log')
+      var source = new EventSource('/debug/logs')
+      source.onmessage = function (event) {
+        var atBottom = window.innerHeight + window.scrollY >= document.body.scrollHeight - 4
+        log.appendChild(document.createTextNode(event.data + '\n'))
+        if (atBottom) window.scrollTo(0, document.body.scrollHeight)
+      }
+    </script>
+  </body>
+</html>
+`
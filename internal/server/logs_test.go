@@ -0,0 +1,139 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/alsamixer-web/internal/config"
+	"github.com/user/alsamixer-web/internal/sse"
+)
+
+func TestLogBroadcasterBacklog(t *testing.T) {
+	b := newLogBroadcaster()
+	for i := 0; i < logBacklog+10; i++ {
+		b.Write([]byte("line\n"))
+	}
+	b.Write([]byte("partial"))
+
+	backlog, ch := b.subscribe()
+	defer b.unsubscribe(ch)
+	if len(backlog) != logBacklog {
+		t.Errorf("expected backlog capped at %d lines, got %d", logBacklog, len(backlog))
+	}
+
+	b.Write([]byte(" line\n"))
+	select {
+	case line := <-ch:
+		if line != "partial line" {
+			t.Errorf("expected partial writes to be joined, got %q", line)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for line")
+	}
+}
+
+func TestDebugLogsDisabledByDefault(t *testing.T) {
+	cfg := &config.Config{
+		Port:     0,
+		BindAddr: "127.0.0.1",
+	}
+	srv := newTestServer(t, cfg, sse.NewHub())
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/logs", nil)
+	resp := httptest.NewRecorder()
+	srv.mux.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusNotFound {
+		t.Errorf("expected status %d without --debug-logs, got %d", http.StatusNotFound, resp.Code)
+	}
+}
+
+func TestDebugLogsStream(t *testing.T) {
+	origOutput := log.Writer()
+	t.Cleanup(func() { log.SetOutput(origOutput) })
+
+	cfg := &config.Config{
+		Port:      0,
+		BindAddr:  "127.0.0.1",
+		DebugLogs: true,
+	}
+	srv := newTestServer(t, cfg, sse.NewHub())
+	if log.Writer() != origOutput {
+		t.Fatal("expected NewServer to leave the log output alone")
+	}
+	log.SetOutput(io.MultiWriter(origOutput, srv.LogWriter()))
+
+	ts := httptest.NewServer(srv.mux)
+	t.Cleanup(ts.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/debug/logs", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("expected text/event-stream, got %q", ct)
+	}
+
+	log.Printf("debug-logs-marker")
+
+	found := make(chan bool, 1)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if strings.HasPrefix(scanner.Text(), "data: ") && strings.Contains(scanner.Text(), "debug-logs-marker") {
+				found <- true
+				return
+			}
+		}
+		found <- false
+	}()
+
+	select {
+	case ok := <-found:
+		if !ok {
+			t.Fatal("stream closed before the logged line arrived")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the logged line on /debug/logs")
+	}
+}
+
+func TestDebugLogsRequiresAdminToken(t *testing.T) {
+	cfg := &config.Config{
+		Port:       0,
+		BindAddr:   "127.0.0.1",
+		DebugLogs:  true,
+		AdminToken: "secret",
+	}
+	srv := newTestServer(t, cfg, sse.NewHub())
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/logs", nil)
+	resp := httptest.NewRecorder()
+	srv.mux.ServeHTTP(resp, req)
+	if resp.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status %d without the admin token, got %d", http.StatusUnauthorized, resp.Code)
+	}
+
+	req.Header.Set("Authorization", "Bearer secret")
+	resp = httptest.NewRecorder()
+	srv.mux.ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Errorf("expected status %d with the admin token, got %d", http.StatusOK, resp.Code)
+	}
+}
@@ -0,0 +1,180 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// rampInterval is the delay between intermediate volume steps.
+	rampInterval = 25 * time.Millisecond
+	// maxRamp bounds how long a single fade may run.
+	maxRamp = 10 * time.Second
+)
+
+// rampTracker keeps the cancel function of the in-flight ramp for each
+// control so a newer request for the same control can take over.
+type rampTracker struct {
+	mu    sync.Mutex
+	ramps map[string]*rampHandle
+	wg    sync.WaitGroup
+}
+
+type rampHandle struct {
+	cancel context.CancelFunc
+	done   chan struct{} // closed once the ramp has finished
+}
+
+func rampKey(cardID uint, control string) string {
+	return fmt.Sprintf("%d|%s", cardID, control)
+}
+
+// start cancels any ramp in flight for key and registers a new one,
+// returning its context and a function to call once it has finished. The
+// old ramp is swapped out in the same critical section, so of several
+// concurrent starts for one key only the last stays registered, and each
+// waits for the ramp it replaced to exit.
+func (t *rampTracker) start(key string) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	h := &rampHandle{cancel: cancel, done: make(chan struct{})}
+
+	t.mu.Lock()
+	if t.ramps == nil {
+		t.ramps = make(map[string]*rampHandle)
+	}
+	old, ok := t.ramps[key]
+	if ok {
+		old.cancel()
+	}
+	t.ramps[key] = h
+	t.mu.Unlock()
+	if ok {
+		<-old.done
+	}
+
+	return ctx, func() {
+		cancel()
+		t.mu.Lock()
+		if t.ramps[key] == h {
+			delete(t.ramps, key)
+		}
+		t.mu.Unlock()
+		close(h.done)
+	}
+}
+
+// cancel stops the ramp in flight for key, if any, and waits for it to
+// exit so that it cannot set another step after the caller's change.
+func (t *rampTracker) cancel(key string) {
+	t.mu.Lock()
+	h, ok := t.ramps[key]
+	if ok {
+		h.cancel()
+		delete(t.ramps, key)
+	}
+	t.mu.Unlock()
+	if ok {
+		<-h.done
+	}
+}
+
+// cancelAll cancels every in-flight ramp and waits for them to exit, so
+// none of them sets another step after a bulk change.
+func (t *rampTracker) cancelAll() {
+	t.mu.Lock()
+	handles := make([]*rampHandle, 0, len(t.ramps))
+	for key, h := range t.ramps {
+		h.cancel()
+		delete(t.ramps, key)
+		handles = append(handles, h)
+	}
+	t.mu.Unlock()
+	for _, h := range handles {
+		<-h.done
+	}
+}
+
+// stop cancels every in-flight ramp and waits for them to exit.
+func (t *rampTracker) stop() {
+	t.cancelAll()
+	t.wg.Wait()
+}
+
+// parseRamp reads the optional "ramp" parameter (milliseconds). A missing
+// or zero value means the change is applied instantly.
+func parseRamp(raw string) (time.Duration, error) {
+	if raw == "" {
+		return 0, nil
+	}
+	ms, err := strconv.Atoi(raw)
+	if err != nil || ms < 0 {
+		return 0, fmt.Errorf("invalid ramp %q", raw)
+	}
+	d := time.Duration(ms) * time.Millisecond
+	if d > maxRamp {
+		d = maxRamp
+	}
+	return d, nil
+}
+
+// startVolumeRamp fades control from its current volume to target over
+// duration on a background goroutine, replacing any ramp already running
+// for the same control.
+func (s *Server) startVolumeRamp(cardID uint, control string, target int, duration time.Duration) {
+	ctx, done := s.ramps.start(rampKey(cardID, control))
+	s.ramps.wg.Add(1)
+	go func() {
+		defer s.ramps.wg.Done()
+		defer done()
+		s.rampVolume(ctx, cardID, control, target, duration)
+	}()
+}
+
+// rampVolume issues intermediate SetVolume calls every rampInterval,
+// broadcasting each step so clients follow the fade. The final step always
+// lands exactly on target unless the ramp is cancelled first; only it is
+// recorded in the control's history.
+func (s *Server) rampVolume(ctx context.Context, cardID uint, control string, target int, duration time.Duration) {
+	m := s.mixer
+
+	from := target
+	if volumes, err := m.GetVolume(cardID, control); err == nil && len(volumes) > 0 {
+		from = volumes[0]
+	}
+	muted, _ := m.GetMute(cardID, strings.Replace(control, " Volume", " Switch", 1))
+
+	steps := int(duration / rampInterval)
+	if steps < 1 {
+		steps = 1
+	}
+
+	log.Printf("[ramp] %s on card %d: %d -> %d over %v (%d steps)", control, cardID, from, target, duration, steps)
+
+	ticker := time.NewTicker(rampInterval)
+	defer ticker.Stop()
+
+	for i := 1; i <= steps; i++ {
+		volume := from + (target-from)*i/steps
+		if err := m.SetVolume(cardID, control, []int{volume}); err != nil {
+			log.Printf("[ramp] failed to set %s to %d: %v", control, volume, err)
+			return
+		}
+		if i == steps {
+			s.broadcastControl(cardID, control, volume, muted)
+			return
+		}
+		s.publishControl(cardID, control, volume, muted, nil)
+
+		select {
+		case <-ctx.Done():
+			log.Printf("[ramp] %s on card %d cancelled at %d", control, cardID, volume)
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/user/alsamixer-web/internal/config"
+	"github.com/user/alsamixer-web/internal/sse"
+)
+
+func TestParseRamp(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"0", 0, false},
+		{"500", 500 * time.Millisecond, false},
+		{"60000", maxRamp, false},
+		{"-1", 0, true},
+		{"soon", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseRamp(tt.raw)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseRamp(%q) error = %v, wantErr %v", tt.raw, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("parseRamp(%q) = %v, want %v", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func postVolume(srv *Server, volume, ramp string) *httptest.ResponseRecorder {
+	form := url.Values{}
+	form.Set("card", "0")
+	form.Set("control", "Master Playback Volume")
+	form.Set("volume", volume)
+
+	target := "/control/volume"
+	if ramp != "" {
+		target += "?ramp=" + ramp
+	}
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp := httptest.NewRecorder()
+	srv.VolumeHandler(resp, req)
+	return resp
+}
+
+func TestRampTrackerConcurrentStart(t *testing.T) {
+	for round := 0; round < 50; round++ {
+		concurrentRampStarts(t)
+	}
+}
+
+// concurrentRampStarts starts many ramps for one control at once and checks
+// that exactly one is left running.
+func concurrentRampStarts(t *testing.T) {
+	t.Helper()
+	var tracker rampTracker
+	const starts = 20
+	key := rampKey(0, "Master Playback Volume")
+
+	contexts := make(chan context.Context, starts)
+	ready := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < starts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-ready
+			ctx, done := tracker.start(key)
+			contexts <- ctx
+			// Each ramp runs until it is cancelled.
+			go func() {
+				<-ctx.Done()
+				done()
+			}()
+		}()
+	}
+	close(ready)
+	wg.Wait()
+	close(contexts)
+
+	var live []context.Context
+	for ctx := range contexts {
+		if ctx.Err() == nil {
+			live = append(live, ctx)
+		}
+	}
+	if len(live) != 1 {
+		t.Fatalf("expected exactly one ramp left running, got %d", len(live))
+	}
+	tracker.cancel(key)
+	if live[0].Err() == nil {
+		t.Error("expected cancel to stop the remaining ramp")
+	}
+}
+
+func TestVolumeHandler_Ramp(t *testing.T) {
+	cfg := &config.Config{
+		Port:     0,
+		BindAddr: "127.0.0.1",
+	}
+	hub := sse.NewHub()
+	srv := newTestServer(t, cfg, hub)
+
+	fm := &fakeMixer{}
+	srv.useMixer(fm)
+
+	// fakeMixer reports 75%, so this fades 75 -> 25 in 4 steps.
+	resp := postVolume(srv, "25", "100")
+	if resp.Code != http.StatusAccepted {
+		t.Fatalf("expected status %d, got %d", http.StatusAccepted, resp.Code)
+	}
+	srv.ramps.wg.Wait()
+
+	fm.mu.Lock()
+	history := fm.history
+	fm.mu.Unlock()
+
+	if len(history) < 3 {
+		t.Fatalf("expected several intermediate sets, got %v", history)
+	}
+	for _, values := range history[:len(history)-1] {
+		if values[0] <= 25 || values[0] >= 75 {
+			t.Errorf("expected intermediate value between 25 and 75, got %v", values)
+		}
+	}
+	if last := history[len(history)-1]; len(last) != 1 || last[0] != 25 {
+		t.Errorf("expected final value [25], got %v", last)
+	}
+
+	// Only where the fade ends up belongs in the control's history.
+	recorded := srv.history.get(0, "Master Playback Volume")
+	if len(recorded) != 1 || len(recorded[0].Volume) != 1 || recorded[0].Volume[0] != 25 {
+		t.Errorf("expected only the final value in the history, got %+v", recorded)
+	}
+}
+
+func TestVolumeHandler_RampCancelledByNewRequest(t *testing.T) {
+	cfg := &config.Config{
+		Port:     0,
+		BindAddr: "127.0.0.1",
+	}
+	hub := sse.NewHub()
+	srv := newTestServer(t, cfg, hub)
+
+	fm := &fakeMixer{}
+	srv.useMixer(fm)
+
+	if resp := postVolume(srv, "0", "2000"); resp.Code != http.StatusAccepted {
+		t.Fatalf("expected status %d, got %d", http.StatusAccepted, resp.Code)
+	}
+	time.Sleep(3 * rampInterval)
+
+	if resp := postVolume(srv, "60", ""); resp.Code != http.StatusNoContent {
+		t.Fatalf("expected status %d, got %d", http.StatusNoContent, resp.Code)
+	}
+	srv.ramps.wg.Wait()
+
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+	if last := fm.history[len(fm.history)-1]; last[0] != 60 {
+		t.Errorf("expected instant set to win over the cancelled ramp, history %v", fm.history)
+	}
+	if len(fm.history) > 10 {
+		t.Errorf("expected ramp to stop early, got %d sets", len(fm.history))
+	}
+}
+
+func TestHandlerBroadcastIncludesTimestamp(t *testing.T) {
+	cfg := &config.Config{
+		Port:     0,
+		BindAddr: "127.0.0.1",
+	}
+	hub := sse.NewHub()
+	go hub.Run()
+	srv := newTestServer(t, cfg, hub)
+
+	fm := &fakeMixer{}
+	srv.useMixer(fm)
+
+	ts := httptest.NewServer(srv.mux)
+	t.Cleanup(ts.Close)
+	events := subscribeEvents(t, ts.URL, hub)
+
+	before := time.Now().UnixMilli()
+	if resp := postVolume(srv, "50", "10"); resp.Code != http.StatusAccepted {
+		t.Fatalf("expected status %d, got %d", http.StatusAccepted, resp.Code)
+	}
+	srv.ramps.wg.Wait()
+
+	data := waitForEvent(t, events, "mixer-update", time.Second)
+	var payload struct {
+		Source    string `json:"source"`
+		Timestamp int64  `json:"timestamp"`
+	}
+	if err := json.Unmarshal([]byte(data), &payload); err != nil {
+		t.Fatalf("decoding event data %q: %v", data, err)
+	}
+	if payload.Source != "handler" {
+		t.Errorf("expected source handler, got %q", payload.Source)
+	}
+	if payload.Timestamp < before || payload.Timestamp > time.Now().UnixMilli() {
+		t.Errorf("expected a unix millis timestamp >= %d, got %d", before, payload.Timestamp)
+	}
+}
@@ -1,12 +1,18 @@
 package server
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"html/template"
+	"io"
+	"io/fs"
 	"log"
 	"math"
+	"net"
 	"net/http"
+	"os"
 	"regexp"
 	"strconv"
 	"strings"
@@ -25,8 +31,18 @@ type Server struct {
 	mux     *http.ServeMux
 	server  *http.Server
 	tmpl    *template.Template
-	mixer   *alsa.Mixer
+	mixer   mixer // shared by all requests and the monitor; see useMixer
+	hw      mixer // mixer without useMixer's wrappers, for optional interfaces
 	monitor *alsa.Monitor
+
+	capabilities capabilitiesCache
+	ramps        rampTracker
+	logs         *logBroadcaster // nil unless --debug-logs is set
+	latency      *latencyStats
+	dedupe       broadcastDedupe
+	history      controlHistory
+	actionToken  string // required by GET actions; see MuteActionHandler
+	started      time.Time
 }
 
 type Theme string
@@ -65,6 +81,8 @@ type pageData struct {
 	SelectedCard uint
 	DefaultCard  uint
 	AllCards     []alsa.Card
+	ReadOnly     bool
+	Shortcuts    map[string][]string
 }
 
 type cardView struct {
@@ -72,6 +90,39 @@ type cardView struct {
 	Name        string
 	Description string
 	Controls    []controlView
+	Groups      []controlGroup
+}
+
+// controlGroup is a titled section of a card's controls, matching
+// alsamixer's Playback and Capture tabs.
+type controlGroup struct {
+	Title    string
+	View     string
+	Controls []controlView
+}
+
+// groupControls splits controls by View into a Playback group followed by
+// a Capture group. Empty groups are omitted.
+func groupControls(controls []controlView) []controlGroup {
+	groups := []controlGroup{
+		{Title: "Playback", View: "playback"},
+		{Title: "Capture", View: "capture"},
+	}
+	for _, ctrl := range controls {
+		for i := range groups {
+			if ctrl.View == groups[i].View {
+				groups[i].Controls = append(groups[i].Controls, ctrl)
+			}
+		}
+	}
+
+	result := make([]controlGroup, 0, len(groups))
+	for _, g := range groups {
+		if len(g.Controls) > 0 {
+			result = append(result, g)
+		}
+	}
+	return result
 }
 
 type controlView struct {
@@ -81,19 +132,104 @@ type controlView struct {
 	BaseName         string
 	Description      string
 	HasVolume        bool
+	Kind             ControlKind
+	RawNow           int  // first channel's raw value, shown by steppers
+	IsPrimary        bool // the card's main control; see primaryControl
 	HasMute          bool
+	MuteState        MuteState
 	HasCapture       bool
 	VolumeMin        int
 	VolumeMax        int
 	VolumeStep       int
 	VolumeNow        int
 	VolumeText       string
+	RawMin           int64 // hardware range, shown in the slider tooltip
+	RawMax           int64
+	Channels         int
 	VolumeAriaLabel  string
 	MuteAriaLabel    string
 	CaptureAriaLabel string
 	Muted            bool
 	CaptureActive    bool
 	View             string
+	ReadOnly         bool
+}
+
+// ControlKind tells the template how to render an integer control.
+type ControlKind string
+
+const (
+	ControlKindVolume  ControlKind = "volume"  // percentage slider
+	ControlKindStepper ControlKind = "stepper" // a setting stepped through its raw values
+)
+
+// maxStepperRange is the widest raw range treated as a setting rather than
+// a level, e.g. a "Channel Mode" of 0-2, unless the name says otherwise.
+const maxStepperRange = 3
+
+// levelKeywords mark a control as a level even when its range is small.
+var levelKeywords = []string{"master", "pcm", "headphone", "speaker", "front", "capture", "gain", "level", "volume"}
+
+// controlKind classifies an integer control. One with only a few raw values
+// and a name that does not sound like a level is most likely an index, and
+// a 0-100% slider over it would be meaningless.
+func controlKind(ctrl alsa.Control) ControlKind {
+	if ctrl.Max-ctrl.Min > maxStepperRange {
+		return ControlKindVolume
+	}
+	name := strings.ToLower(extractBaseName(ctrl.Name))
+	for _, keyword := range levelKeywords {
+		if strings.Contains(name, keyword) {
+			return ControlKindVolume
+		}
+	}
+	return ControlKindStepper
+}
+
+// primaryControl picks the control alsamixer would treat as the card's
+// main one: Master, then PCM, then the first volume control. It returns ""
+// if the card has no volume control.
+func primaryControl(controls []alsa.Control) string {
+	for _, preferred := range []string{"master", "pcm"} {
+		for _, ctrl := range controls {
+			if ctrl.Type == "integer" && strings.EqualFold(extractBaseName(ctrl.Name), preferred) {
+				return ctrl.Name
+			}
+		}
+	}
+	for _, ctrl := range controls {
+		if ctrl.Type == "integer" {
+			return ctrl.Name
+		}
+	}
+	return ""
+}
+
+// MuteState tells the template whether a control can be muted, so that a
+// control without a switch is not rendered as simply unmuted.
+type MuteState string
+
+const (
+	MuteNone    MuteState = "none"    // the control has no switch
+	MuteUnknown MuteState = "unknown" // the switch exists but could not be read
+	MuteMuted   MuteState = "muted"
+	MuteUnmuted MuteState = "unmuted"
+)
+
+// muteStateFor derives the MuteState of a control from the result of
+// reading its switch. A failed read only means "no switch" when the card
+// does not list a switch of that name.
+func muteStateFor(controls []alsa.Control, switchName string, muted bool, err error) MuteState {
+	switch {
+	case err == nil && muted:
+		return MuteMuted
+	case err == nil:
+		return MuteUnmuted
+	}
+	if _, ok := findControl(controls, switchName); ok {
+		return MuteUnknown
+	}
+	return MuteNone
 }
 
 var nonAlphaNum = regexp.MustCompile(`[^a-z0-9]+`)
@@ -174,7 +310,7 @@ func (s *Server) loadCardsForFilter(selectedCardID int, viewMode ViewMode) []car
 		return nil
 	}
 
-	cards, err := s.mixer.ListCards()
+	cards, err := s.listCards()
 	if err != nil {
 		log.Printf("failed to list cards: %v", err)
 		return nil
@@ -195,6 +331,7 @@ func (s *Server) loadCardsForFilter(selectedCardID int, viewMode ViewMode) []car
 			continue
 		}
 
+		primary := primaryControl(controls)
 		for _, ctrl := range controls {
 			// Only show controls that have volume (integer type with range)
 			if ctrl.Type != "integer" {
@@ -243,11 +380,19 @@ func (s *Server) loadCardsForFilter(selectedCardID int, viewMode ViewMode) []car
 			if err == nil && len(volumes) > 0 {
 				volumeNow = volumes[0]
 			}
+			kind := controlKind(ctrl)
+			rawNow := 0
+			if kind == ControlKindStepper {
+				if raw, err := s.mixer.GetRawVolume(card.ID, ctrl.Name); err == nil && len(raw) > 0 {
+					rawNow = raw[0]
+				}
+			}
 
 			// Check if there's a corresponding mute switch (ends with " Switch")
 			muteControlName := strings.Replace(ctrl.Name, " Volume", " Switch", 1)
 			muted, muteErr := s.mixer.GetMute(card.ID, muteControlName)
 			hasMute := muteErr == nil
+			muteState := muteStateFor(controls, muteControlName, muted, muteErr)
 
 			// Check if there's a corresponding capture switch (for capture controls)
 			var hasCapture bool
@@ -265,7 +410,11 @@ func (s *Server) loadCardsForFilter(selectedCardID int, viewMode ViewMode) []car
 				Name:       ctrl.Name,
 				BaseName:   extractBaseName(ctrl.Name),
 				HasVolume:  true,
+				Kind:       kind,
+				RawNow:     rawNow,
+				IsPrimary:  ctrl.Name == primary,
 				HasMute:    hasMute,
+				MuteState:  muteState,
 				HasCapture: hasCapture,
 				VolumeMin:  0,
 				VolumeMax:  100,
@@ -273,24 +422,86 @@ func (s *Server) loadCardsForFilter(selectedCardID int, viewMode ViewMode) []car
 				VolumeStep:       int(math.Ceil(100.0 / float64(ctrl.Max-ctrl.Min+1))),
 				VolumeNow:        volumeNow,
 				VolumeText:       fmt.Sprintf("%d%%", volumeNow),
+				RawMin:           ctrl.Min,
+				RawMax:           ctrl.Max,
+				Channels:         ctrl.Count,
 				VolumeAriaLabel:  fmt.Sprintf("%s volume", ctrl.Name),
 				MuteAriaLabel:    fmt.Sprintf("%s mute", ctrl.Name),
 				CaptureAriaLabel: fmt.Sprintf("%s capture", ctrl.Name),
 				Muted:            muted,
 				CaptureActive:    captureActive,
 				View:             view,
+				ReadOnly:         s.config.ReadOnly,
 			})
 		}
 
+		cv.Groups = groupControls(cv.Controls)
 		result = append(result, cv)
 	}
 
 	return result
 }
 
-func mustParseTemplates() *template.Template {
-	// Use embed.TemplateFS() to get the embedded filesystem
-	return template.Must(template.ParseFS(web.TemplateFS(), "base.html", "index.html", "controls.html"))
+// templateFS is the filesystem the page templates are parsed from. Tests
+// may override it.
+var templateFS = web.TemplateFS
+
+// parseTemplates parses the page templates from fsys.
+func parseTemplates(fsys fs.FS) (*template.Template, error) {
+	tmpl, err := template.ParseFS(fsys, "base.html", "index.html", "controls.html", "notfound.html")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse templates: %w", err)
+	}
+	return tmpl, nil
+}
+
+// samplePage exercises every branch of the page templates: a writable
+// slider with mute and capture switches, a read-only stepper without a
+// mute switch, and a slider whose mute state could not be read.
+func samplePage() pageData {
+	controls := []controlView{
+		{
+			ID: "master", Name: "Master", BaseName: "Master", Description: "Main output",
+			HasVolume: true, Kind: ControlKindVolume, IsPrimary: true, HasMute: true, MuteState: MuteMuted, Muted: true,
+			HasCapture: true, CaptureActive: true, VolumeMax: 100, VolumeStep: 1, VolumeNow: 50,
+			VolumeText: "50%", RawMax: 87, Channels: 2, View: "playback",
+		},
+		{
+			ID: "input-source", Name: "Input Source", BaseName: "Input Source",
+			HasVolume: true, Kind: ControlKindStepper, MuteState: MuteNone, RawMax: 2, Channels: 1,
+			View: "capture", ReadOnly: true,
+		},
+		{
+			ID: "pcm", Name: "PCM", BaseName: "PCM", HasVolume: true, Kind: ControlKindVolume,
+			MuteState: MuteUnknown, HasCapture: true, Channels: 1, View: "playback", ReadOnly: true,
+		},
+	}
+	card := alsa.Card{ID: 0, Name: "PCH", LongName: "HDA Intel PCH"}
+	return pageData{
+		Theme:     string(ThemeLinuxConsole),
+		Cards:     []cardView{{ID: card.ID, Name: card.Name, Description: card.LongName, Controls: controls, Groups: groupControls(controls)}},
+		AllCards:  []alsa.Card{card},
+		Shortcuts: config.DefaultShortcuts,
+	}
+}
+
+// checkTemplates renders the page, each control of samplePage and the 404
+// page, so that a template referring to a field the views no longer have
+// fails at startup instead of on the first request.
+func checkTemplates(tmpl *template.Template) error {
+	page := samplePage()
+	if err := tmpl.ExecuteTemplate(io.Discard, "base", page); err != nil {
+		return fmt.Errorf("templates do not match the page data: %w", err)
+	}
+	for _, ctrl := range page.Cards[0].Controls {
+		if err := tmpl.ExecuteTemplate(io.Discard, "control", ctrl); err != nil {
+			return fmt.Errorf("templates do not match the control data: %w", err)
+		}
+	}
+	if err := tmpl.ExecuteTemplate(io.Discard, "notfound", notFoundData{Theme: page.Theme, Path: "/missing"}); err != nil {
+		return fmt.Errorf("templates do not match the not found data: %w", err)
+	}
+	return nil
 }
 
 func (s *Server) renderControlHTML(ctrl controlView) (string, error) {
@@ -317,11 +528,19 @@ func (s *Server) getControlView(cardID uint, controlName string) *controlView {
 		if err == nil && len(volumes) > 0 {
 			volumeNow = volumes[0]
 		}
+		kind := controlKind(ctrl)
+		rawNow := 0
+		if kind == ControlKindStepper {
+			if raw, err := s.mixer.GetRawVolume(cardID, controlName); err == nil && len(raw) > 0 {
+				rawNow = raw[0]
+			}
+		}
 
 		// Check if there's a corresponding mute switch (replace " Volume" with " Switch")
 		muteControlName := strings.Replace(controlName, " Volume", " Switch", 1)
 		muted, muteErr := s.mixer.GetMute(cardID, muteControlName)
 		hasMute := muteErr == nil
+		muteState := muteStateFor(controls, muteControlName, muted, muteErr)
 
 		view := controlViewType(ctrl.Name)
 
@@ -341,7 +560,11 @@ func (s *Server) getControlView(cardID uint, controlName string) *controlView {
 			Name:       ctrl.Name,
 			BaseName:   extractBaseName(ctrl.Name),
 			HasVolume:  ctrl.Type == "integer",
+			Kind:       kind,
+			RawNow:     rawNow,
+			IsPrimary:  ctrl.Name == primaryControl(controls),
 			HasMute:    hasMute,
+			MuteState:  muteState,
 			HasCapture: hasCapture,
 			VolumeMin:  0,
 			VolumeMax:  100,
@@ -350,12 +573,16 @@ func (s *Server) getControlView(cardID uint, controlName string) *controlView {
 			VolumeStep:       int(math.Ceil(100.0 / float64(ctrl.Max-ctrl.Min+1))),
 			VolumeNow:        volumeNow,
 			VolumeText:       fmt.Sprintf("%d%%", volumeNow),
+			RawMin:           ctrl.Min,
+			RawMax:           ctrl.Max,
+			Channels:         ctrl.Count,
 			VolumeAriaLabel:  fmt.Sprintf("%s volume", ctrl.Name),
 			MuteAriaLabel:    fmt.Sprintf("%s mute", ctrl.Name),
 			CaptureAriaLabel: fmt.Sprintf("%s capture", ctrl.Name),
 			Muted:            muted,
 			CaptureActive:    captureActive,
 			View:             view,
+			ReadOnly:         s.config.ReadOnly,
 		}
 	}
 
@@ -375,27 +602,73 @@ func normalizeTheme(raw string) Theme {
 	return t
 }
 
-// NewServer creates a new HTTP server instance.
-func NewServer(cfg *config.Config, hub *sse.Hub) *Server {
+// NewServer creates a new HTTP server instance. It fails if the page
+// templates cannot be parsed.
+func NewServer(cfg *config.Config, hub *sse.Hub) (*Server, error) {
+	tmpl, err := parseTemplates(templateFS())
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTemplates(tmpl); err != nil {
+		return nil, err
+	}
+
+	latency := newLatencyStats()
+	alsaMixer := alsa.NewMixer()
+	alsaMixer.SetHandleIdleTimeout(cfg.HandleIdleTimeout)
 	s := &Server{
-		config: cfg,
-		hub:    hub,
-		mux:    http.NewServeMux(),
-		mixer:  alsa.NewMixer(),
+		config:  cfg,
+		hub:     hub,
+		mux:     http.NewServeMux(),
+		tmpl:    tmpl,
+		latency: latency,
+		started: time.Now(),
+	}
+	s.useMixer(alsaMixer)
+
+	// A generated token is a secret, so it goes to stderr rather than the
+	// log, which --debug-logs serves over HTTP.
+	if cfg.AllowGetActions {
+		s.actionToken = cfg.ActionToken
+		if s.actionToken == "" {
+			if s.actionToken, err = newActionToken(); err != nil {
+				return nil, err
+			}
+			fmt.Fprintf(os.Stderr, "GET action token: %s (set --action-token to keep it across restarts)\n", s.actionToken)
+		}
+		log.Printf("GET actions enabled")
 	}
 
-	if s.mixer == nil {
-		log.Printf("ALSA mixer unavailable; continuing without monitor")
-	} else if !s.mixer.IsOpen() {
+	if cfg.DebugLogs {
+		s.logs = newLogBroadcaster()
+		log.Printf("WARNING: serving the application log at /debug/logs")
+	}
+
+	if cfg.LogLevel == "debug" && hub != nil {
+		hub.SetLogPayloadSizes(true)
+	}
+
+	if cfg.DryRun {
+		log.Printf("Dry-run mode: control changes are logged and broadcast but not applied")
+	}
+
+	if !s.mixer.IsOpen() {
 		log.Printf("ALSA mixer not open; continuing without monitor")
 	} else {
 		s.monitor = alsa.NewMonitor(s.mixer, s.hub, cfg.MonitorFile)
+		s.monitor.OnTopologyChange(s.capabilities.invalidate)
+		s.monitor.OnChange(s.monitorChanged)
+		s.monitor.SetVolumeThreshold(cfg.VolumeThreshold)
+		s.monitor.SetPollInterval(cfg.PollInterval, cfg.IdlePollInterval)
+		if len(cfg.ExposeCards) > 0 {
+			s.monitor.SetCardFilter(func(card alsa.Card) bool {
+				return cardExposed(card, cfg.ExposeCards)
+			})
+		}
 	}
-	s.tmpl = mustParseTemplates()
-
 	s.setupRoutes()
 
-	addr := fmt.Sprintf("%s:%d", cfg.BindAddr, cfg.Port)
+	addr := net.JoinHostPort(cfg.BindAddr, strconv.Itoa(cfg.Port))
 	s.server = &http.Server{
 		Addr:         addr,
 		Handler:      s.loggingMiddleware(s.corsMiddleware(s.mux)),
@@ -404,7 +677,148 @@ func NewServer(cfg *config.Config, hub *sse.Hub) *Server {
 		IdleTimeout:  60 * time.Second,
 	}
 
-	return s
+	return s, nil
+}
+
+// cardExposed reports whether card matches an entry of the --expose-card
+// list, by index, name or long name. An empty list exposes every card.
+func cardExposed(card alsa.Card, expose []string) bool {
+	if len(expose) == 0 {
+		return true
+	}
+	id := strconv.FormatUint(uint64(card.ID), 10)
+	for _, e := range expose {
+		if e == id || e == card.Name || (card.LongName != "" && e == card.LongName) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterExposedCards returns the cards that --expose-card allows.
+func filterExposedCards(cards []alsa.Card, expose []string) []alsa.Card {
+	if len(expose) == 0 {
+		return cards
+	}
+	exposed := make([]alsa.Card, 0, len(cards))
+	for _, card := range cards {
+		if cardExposed(card, expose) {
+			exposed = append(exposed, card)
+		}
+	}
+	return exposed
+}
+
+// listCards lists the cards the web UI and API are allowed to show.
+func (s *Server) listCards() ([]alsa.Card, error) {
+	cards, err := s.mixer.ListCards()
+	if err != nil {
+		return nil, err
+	}
+	return filterExposedCards(cards, s.config.ExposeCards), nil
+}
+
+// requireExposedCard rejects control requests for cards hidden by
+// --expose-card. Requests whose card cannot be parsed are passed through
+// for the handler to reject.
+func (s *Server) requireExposedCard(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if len(s.config.ExposeCards) == 0 {
+			next(w, r)
+			return
+		}
+
+		cardStr := r.PathValue("cardId")
+		if cardStr == "" {
+			cardStr = r.FormValue("card")
+		}
+		cardID, err := strconv.ParseUint(cardStr, 10, 0)
+		if err != nil {
+			next(w, r)
+			return
+		}
+
+		cards, _ := s.listCards()
+		for _, card := range cards {
+			if card.ID == uint(cardID) {
+				next(w, r)
+				return
+			}
+		}
+		http.Error(w, "card not found", http.StatusNotFound)
+	}
+}
+
+// requireExposedGroup rejects requests for a --group with a member on a
+// card hidden by --expose-card, so a group cannot reach cards that
+// requireExposedCard protects. Unknown groups are passed through for the
+// handler to reject.
+func (s *Server) requireExposedGroup(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		members, ok := s.config.Groups[r.PathValue("name")]
+		if len(s.config.ExposeCards) == 0 || !ok {
+			next(w, r)
+			return
+		}
+
+		cards, _ := s.listCards()
+		for _, member := range members {
+			exposed := false
+			for _, card := range cards {
+				if card.ID == member.Card {
+					exposed = true
+					break
+				}
+			}
+			if !exposed {
+				http.Error(w, fmt.Sprintf("card %d not found", member.Card), http.StatusNotFound)
+				return
+			}
+		}
+		next(w, r)
+	}
+}
+
+// defaultCard picks the card the page shows when none is requested: the
+// --card index if it was given, otherwise the ALSA configuration's default.
+func (s *Server) defaultCard(cards []alsa.Card) uint {
+	configuredDefault := alsa.GetDefaultCard()
+	if s.config.CardSet {
+		configuredDefault = int(s.config.CardIndex)
+	}
+	return alsa.ResolveDefaultCard(cards, configuredDefault)
+}
+
+// resolveCardParam maps the ?card= query parameter to a card ID. It accepts
+// a card index, a card's long name or its short name; the long name is
+// checked first since it is the only way to tell identical devices apart.
+// Anything that does not match a present card resolves to fallback.
+func resolveCardParam(param string, cards []alsa.Card, fallback uint) uint {
+	if param == "" || param == "default" {
+		return fallback
+	}
+
+	if cardNum, err := strconv.ParseUint(param, 10, 0); err == nil {
+		for _, c := range cards {
+			if c.ID == uint(cardNum) {
+				return c.ID
+			}
+		}
+		return fallback
+	}
+
+	for _, c := range cards {
+		if c.LongName != "" && c.LongName == param {
+			return c.ID
+		}
+	}
+	for _, c := range cards {
+		if c.Name == param {
+			return c.ID
+		}
+	}
+
+	return fallback
 }
 
 // Hub returns the SSE hub for use by the monitor
@@ -412,40 +826,94 @@ func (s *Server) Hub() *sse.Hub {
 	return s.hub
 }
 
+// wantsJSON reports whether the request's Accept header prefers JSON over
+// HTML. An explicit application/json beats a bare */*, which browsers and
+// curl send, so those still get the page.
+func wantsJSON(r *http.Request) bool {
+	jsonQ, htmlQ, wildcardQ := 0.0, 0.0, 0.0
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, _ := strings.Cut(part, ";")
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			if v, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+
+		switch strings.ToLower(strings.TrimSpace(mediaType)) {
+		case "application/json":
+			jsonQ = max(jsonQ, q)
+		case "text/html", "application/xhtml+xml", "text/*":
+			htmlQ = max(htmlQ, q)
+		case "*/*":
+			wildcardQ = max(wildcardQ, q)
+		}
+	}
+	return jsonQ > 0 && jsonQ > htmlQ && jsonQ >= wildcardQ
+}
+
+// notFoundData is what the "notfound" template renders.
+type notFoundData struct {
+	Theme string
+	Path  string
+}
+
+// NotFoundHandler answers requests for paths no route serves: with a JSON
+// error for API clients, a 404 page in the requested theme for browsers,
+// and the plain default otherwise.
+func (s *Server) NotFoundHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Vary", "Accept")
+	switch {
+	case wantsJSON(r):
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"error": "not found",
+			"path":  r.URL.Path,
+		})
+	case strings.Contains(r.Header.Get("Accept"), "text/html"):
+		data := notFoundData{
+			Theme: string(normalizeTheme(r.URL.Query().Get("theme"))),
+			Path:  r.URL.Path,
+		}
+		var buf bytes.Buffer
+		if err := s.tmpl.ExecuteTemplate(&buf, "notfound", data); err != nil {
+			log.Printf("failed to render not found template: %v", err)
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = buf.WriteTo(w)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
 // setupRoutes configures all HTTP routes.
 func (s *Server) setupRoutes() {
 	s.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/" {
-			http.NotFound(w, r)
+			s.NotFoundHandler(w, r)
+			return
+		}
+
+		// API clients asking for JSON get the /api/state payload instead
+		w.Header().Add("Vary", "Accept")
+		if wantsJSON(r) {
+			s.StateHandler(w, r)
 			return
 		}
 
 		requestedTheme := r.URL.Query().Get("theme")
 		theme := normalizeTheme(requestedTheme)
 
-		allCards, _ := s.mixer.ListCards()
-		configuredDefault := alsa.GetDefaultCard()
-		resolvedDefault := alsa.ResolveDefaultCard(allCards, configuredDefault)
-
-		cardParam := r.URL.Query().Get("card")
-		var selectedCardID uint
-		if cardParam == "" || cardParam == "default" {
-			selectedCardID = resolvedDefault
-		} else if cardNum, err := strconv.ParseUint(cardParam, 10, 0); err == nil {
-			selectedCardID = uint(cardNum)
-			found := false
-			for _, c := range allCards {
-				if c.ID == selectedCardID {
-					found = true
-					break
-				}
-			}
-			if !found {
-				selectedCardID = resolvedDefault
-			}
-		} else {
-			selectedCardID = resolvedDefault
-		}
+		allCards, _ := s.listCards()
+		resolvedDefault := s.defaultCard(allCards)
+
+		selectedCardID := resolveCardParam(r.URL.Query().Get("card"), allCards, resolvedDefault)
 
 		data := pageData{
 			Theme:        string(theme),
@@ -453,6 +921,8 @@ func (s *Server) setupRoutes() {
 			SelectedCard: selectedCardID,
 			DefaultCard:  resolvedDefault,
 			AllCards:     allCards,
+			ReadOnly:     s.config.ReadOnly,
+			Shortcuts:    s.config.Shortcuts,
 		}
 
 		w.Header().Set("Content-Type", "text/html; charset=utf-8")
@@ -464,24 +934,79 @@ func (s *Server) setupRoutes() {
 	})
 
 	// SSE endpoint
-	s.mux.Handle("/events", s.hub)
+	s.mux.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
+		filter, err := controlEventFilter(r.URL.Query().Get("control"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		// A new subscriber wakes the monitor from its idle interval.
+		if s.monitor != nil {
+			s.monitor.NoteActivity()
+		}
+		s.hub.ServeFiltered(w, r, filter)
+	})
+	s.mux.HandleFunc("GET /events/health", s.hub.ServeHealth)
+	s.mux.HandleFunc("POST /events/refresh", s.EventsRefreshHandler)
+	s.mux.HandleFunc("GET /status", s.StatusHandler)
+	s.mux.HandleFunc("GET /metrics", s.MetricsHandler)
 
 	// Static file server (embedded)
 	staticFS := http.FileServer(http.FS(web.StaticFS()))
 	s.mux.Handle("/static/", http.StripPrefix("/static/", staticFS))
+	s.mux.HandleFunc("GET /favicon.ico", s.FaviconHandler)
+	s.mux.HandleFunc("GET /manifest.json", s.ManifestHandler)
+	s.mux.HandleFunc("GET /sw.js", s.ServiceWorkerHandler)
 
 	// Control endpoints (legacy - keep for backwards compatibility)
-	s.mux.HandleFunc("POST /control/volume", s.VolumeHandler)
-	s.mux.HandleFunc("POST /control/mute", s.MuteHandler)
-	s.mux.HandleFunc("POST /control/capture", s.CaptureHandler)
+	s.mux.HandleFunc("POST /control/volume", s.requireWritable(s.requireExposedCard(s.VolumeHandler)))
+	s.mux.HandleFunc("POST /control/volume/step", s.requireWritable(s.requireExposedCard(s.VolumeStepHandler)))
+	s.mux.HandleFunc("POST /control/volume/db-relative", s.requireWritable(s.requireExposedCard(s.VolumeDBRelativeHandler)))
+	s.mux.HandleFunc("POST /control/mute", s.requireWritable(s.requireExposedCard(s.MuteHandler)))
+	s.mux.HandleFunc("POST /control/capture", s.requireWritable(s.requireExposedCard(s.CaptureHandler)))
+	s.mux.HandleFunc("GET /action/mute", s.requireWritable(s.requireExposedCard(s.MuteActionHandler)))
 
 	// RESTful API endpoints
-	s.mux.HandleFunc("POST /card/{cardId}/control/{controlName}/volume", s.CardControlVolumeHandler)
-	s.mux.HandleFunc("POST /card/{cardId}/control/{controlName}/mute", s.CardControlMuteHandler)
-	s.mux.HandleFunc("POST /card/{cardId}/control/{controlName}/capture", s.CardControlCaptureHandler)
+	s.mux.HandleFunc("POST /card/{cardId}/control/{controlName}/volume", s.requireWritable(s.requireExposedCard(s.CardControlVolumeHandler)))
+	s.mux.HandleFunc("POST /card/{cardId}/control/{controlName}/mute", s.requireWritable(s.requireExposedCard(s.CardControlMuteHandler)))
+	s.mux.HandleFunc("POST /card/{cardId}/control/{controlName}/capture", s.requireWritable(s.requireExposedCard(s.CardControlCaptureHandler)))
+	// The same, for control names the path segment cannot carry; the name
+	// comes from the "control" parameter.
+	s.mux.HandleFunc("POST /card/{cardId}/control/volume", s.requireWritable(s.requireExposedCard(s.CardControlVolumeHandler)))
+	s.mux.HandleFunc("POST /card/{cardId}/control/mute", s.requireWritable(s.requireExposedCard(s.CardControlMuteHandler)))
+	s.mux.HandleFunc("POST /card/{cardId}/control/capture", s.requireWritable(s.requireExposedCard(s.CardControlCaptureHandler)))
+
+	// JSON API endpoints
+	s.mux.HandleFunc("GET /api/capabilities", s.CapabilitiesHandler)
+	s.mux.HandleFunc("GET /api/state", s.StateHandler)
+	s.mux.HandleFunc("POST /api/rescan", s.requireWritable(s.RescanHandler))
+	s.mux.HandleFunc("GET /api/card/{cardId}/control/{controlName}/history", s.requireExposedCard(s.HistoryHandler))
+	s.mux.HandleFunc("GET /api/card/{cardId}/control/history", s.requireExposedCard(s.HistoryHandler))
+	s.mux.HandleFunc("GET /api/group/{name}", s.requireExposedGroup(s.GroupHandler))
+	s.mux.HandleFunc("POST /api/group/{name}/volume", s.requireWritable(s.requireExposedGroup(s.GroupVolumeHandler)))
+	s.mux.HandleFunc("GET /api/export", s.ExportHandler)
+	s.mux.HandleFunc("POST /api/import", s.requireWritable(s.ImportHandler))
 
 	// Debug endpoint
 	s.mux.HandleFunc("GET /debug/controls", s.DebugControlsHandler)
+	s.mux.HandleFunc("GET /debug/monitor", s.DebugMonitorHandler)
+	if s.config.AdminToken != "" {
+		s.mux.HandleFunc("GET /debug/config", s.requireAdmin(s.DebugConfigHandler))
+	} else {
+		s.mux.HandleFunc("GET /debug/config", s.DebugConfigHandler)
+	}
+	if s.logs != nil {
+		if s.config.AdminToken != "" {
+			s.mux.HandleFunc("GET /debug/logs", s.requireAdmin(s.DebugLogsHandler))
+		} else {
+			s.mux.HandleFunc("GET /debug/logs", s.DebugLogsHandler)
+		}
+	}
+
+	// Admin endpoints exist only when a token is configured
+	if s.config.AdminToken != "" {
+		s.mux.HandleFunc("POST /admin/broadcast", s.requireAdmin(s.AdminBroadcastHandler))
+	}
 }
 
 // loggingMiddleware logs all HTTP requests.
@@ -505,6 +1030,22 @@ func (s *Server) loggingMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// requireWritable rejects requests to mutation endpoints with 403 when the
+// server runs in read-only mode. SSE updates keep flowing regardless. In
+// dry-run mode responses carry an X-Dry-Run header.
+func (s *Server) requireWritable(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.config.ReadOnly {
+			http.Error(w, "server is in read-only mode", http.StatusForbidden)
+			return
+		}
+		if s.config.DryRun {
+			w.Header().Set("X-Dry-Run", "true")
+		}
+		next(w, r)
+	}
+}
+
 // corsMiddleware adds CORS headers to allow all origins.
 func (s *Server) corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -547,11 +1088,70 @@ func (rw *responseWriter) Hijack() (interface{}, interface{}, error) {
 
 // Start begins the HTTP server.
 func (s *Server) Start() error {
+	listeners, err := s.listen()
+	if err != nil {
+		return err
+	}
 	log.Printf("Starting server on %s", s.server.Addr)
 	if s.monitor != nil {
 		s.monitor.Start()
 	}
-	return s.server.ListenAndServe()
+	return s.serve(listeners)
+}
+
+// listenNetwork picks the address family for bind, so an IPv4 address only
+// accepts IPv4 connections and an IPv6 address only IPv6 ones. Host names
+// are left to the resolver.
+func listenNetwork(bind string) string {
+	ip := net.ParseIP(bind)
+	switch {
+	case ip == nil:
+		return "tcp"
+	case ip.To4() != nil:
+		return "tcp4"
+	default:
+		return "tcp6"
+	}
+}
+
+// listen opens the server's listeners: one on the bind address or, with
+// --dual-stack, one on the wildcard address of each family.
+func (s *Server) listen() ([]net.Listener, error) {
+	type target struct{ network, addr string }
+	targets := []target{{listenNetwork(s.config.BindAddr), s.server.Addr}}
+	if s.config.DualStack {
+		port := strconv.Itoa(s.config.Port)
+		targets = []target{
+			{"tcp4", net.JoinHostPort("0.0.0.0", port)},
+			{"tcp6", net.JoinHostPort("::", port)},
+		}
+	}
+
+	listeners := make([]net.Listener, 0, len(targets))
+	for _, t := range targets {
+		l, err := net.Listen(t.network, t.addr)
+		if err != nil {
+			for _, opened := range listeners {
+				opened.Close()
+			}
+			return nil, err
+		}
+		listeners = append(listeners, l)
+	}
+	return listeners, nil
+}
+
+// serve handles requests on every listener with the one http.Server and
+// returns when the first of them stops.
+func (s *Server) serve(listeners []net.Listener) error {
+	errCh := make(chan error, len(listeners))
+	for _, l := range listeners {
+		log.Printf("Listening on %s", l.Addr())
+		go func(l net.Listener) {
+			errCh <- s.server.Serve(l)
+		}(l)
+	}
+	return <-errCh
 }
 
 // Stop gracefully shuts down the HTTP server.
@@ -560,17 +1160,55 @@ func (s *Server) Stop(ctx context.Context) error {
 	if s.monitor != nil {
 		s.monitor.Stop()
 	}
-	return s.server.Shutdown(ctx)
+	s.ramps.stop()
+	err := s.server.Shutdown(ctx)
+	s.mixer.Close()
+	return err
+}
+
+// monitorChanged records the changes the monitor saw in the history and
+// clears them from the broadcast dedupe.
+func (s *Server) monitorChanged(delta *alsa.StateSnapshot) {
+	s.history.recordSnapshot(delta)
+	s.dedupe.forgetSnapshot(delta)
+}
+
+// useMixer makes m the mixer for every request and, if created after this,
+// the monitor. Reads are timed and volumes trimmed per --card-trim; with
+// --dry-run writes never reach m.
+func (s *Server) useMixer(m mixer) {
+	s.hw = m
+	m = withTrims(timedMixer{mixer: m, stats: s.latency}, s.config.CardTrims)
+	if s.config.DryRun {
+		m = newDryRunMixer(m)
+	}
+	s.mixer = m
+}
+
+// mixerUnavailableReason explains why the shared mixer cannot be used, or
+// returns an empty string if it is ready.
+func (s *Server) mixerUnavailableReason() string {
+	if s.mixer == nil {
+		return "ALSA mixer failed to open"
+	}
+	return s.mixer.UnavailableReason()
+}
+
+// DebugConfigHandler serves GET /debug/config: the effective configuration,
+// with secrets redacted.
+func (s *Server) DebugConfigHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(s.config.Redacted())
 }
 
 // DebugControlsHandler returns debug info about ALSA controls
 func (s *Server) DebugControlsHandler(w http.ResponseWriter, r *http.Request) {
-	if s.mixer == nil || !s.mixer.IsOpen() {
-		http.Error(w, "mixer not available", http.StatusServiceUnavailable)
+	if reason := s.mixerUnavailableReason(); reason != "" {
+		http.Error(w, "mixer not available: "+reason, http.StatusServiceUnavailable)
 		return
 	}
 
-	cards, err := s.mixer.ListCards()
+	cards, err := s.listCards()
 	if err != nil {
 		http.Error(w, fmt.Sprintf("failed to list cards: %v", err), http.StatusInternalServerError)
 		return
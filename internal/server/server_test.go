@@ -1,14 +1,22 @@
 package server
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
+	"fmt"
 	"io"
+	"io/fs"
 	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	"github.com/user/alsamixer-web/internal/alsa"
@@ -17,18 +25,32 @@ import (
 )
 
 type fakeMixer struct {
-	card    uint
-	control string
-	values  []int
-	called  bool
-	err     error
+	mu       sync.Mutex
+	history  [][]int
+	card     uint
+	control  string
+	values   []int
+	called   bool
+	err      error
+	controls []alsa.Control
+	readBack []int         // if set, returned by GetVolume instead of 75%
+	delay    time.Duration // injected latency for ListControls and GetVolume
+	raw      []int         // raw hardware values for GetRawVolume/SetRawVolume
+	cards    []alsa.Card   // if set, returned by ListCards instead of one test card
 }
 
 func (f *fakeMixer) ListCards() ([]alsa.Card, error) {
+	if f.cards != nil {
+		return f.cards, nil
+	}
 	return []alsa.Card{{ID: 0, Name: "Test Card"}}, nil
 }
 
 func (f *fakeMixer) ListControls(card uint) ([]alsa.Control, error) {
+	time.Sleep(f.delay)
+	if f.controls != nil {
+		return f.controls, nil
+	}
 	return []alsa.Control{
 		{Name: "Master Playback Volume", Type: "integer", Min: 0, Max: 100, Step: 1, Count: 2},
 		{Name: "Master Playback Switch", Type: "boolean"},
@@ -36,14 +58,72 @@ func (f *fakeMixer) ListControls(card uint) ([]alsa.Control, error) {
 }
 
 func (f *fakeMixer) GetVolume(card uint, control string) ([]int, error) {
+	time.Sleep(f.delay)
+	if f.readBack != nil {
+		return f.readBack, nil
+	}
 	return []int{75, 75}, nil
 }
 
+func (f *fakeMixer) GetRawVolume(card uint, control string) ([]int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]int(nil), f.raw...), nil
+}
+
+func (f *fakeMixer) SetRawVolume(card uint, control string, values []int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.raw = append([]int(nil), values...)
+	f.control = control
+	f.called = true
+	return f.err
+}
+
+func (f *fakeMixer) GetVolumeDB(card uint, control string) ([]float64, error) {
+	return nil, fmt.Errorf("control has no dB scale")
+}
+
+func (f *fakeMixer) SetVolumeDB(card uint, control string, values []float64) error {
+	return fmt.Errorf("control has no dB scale")
+}
+
+func (f *fakeMixer) VolumeDBRange(card uint, control string) (float64, float64, error) {
+	return 0, 0, fmt.Errorf("control has no dB scale")
+}
+
 func (f *fakeMixer) Close() error { return nil }
 
 func (f *fakeMixer) IsOpen() bool { return true }
 
+func (f *fakeMixer) UnavailableReason() string { return "" }
+
+func (f *fakeMixer) HasPlaybackVolume(card uint, control string) (bool, error) {
+	return strings.Contains(control, "Playback Volume"), nil
+}
+
+func (f *fakeMixer) HasPlaybackSwitch(card uint, control string) (bool, error) {
+	return strings.Contains(control, "Playback Switch"), nil
+}
+
+func (f *fakeMixer) HasCaptureVolume(card uint, control string) (bool, error) {
+	return strings.Contains(control, "Capture Volume"), nil
+}
+
+func (f *fakeMixer) HasCaptureSwitch(card uint, control string) (bool, error) {
+	return strings.Contains(control, "Capture Switch"), nil
+}
+
+func (f *fakeMixer) Supports(card uint, control string, op alsa.Operation) (bool, error) {
+	return alsa.SupportsOperation(f, card, control, op)
+}
+
 func (f *fakeMixer) GetMute(card uint, control string) (bool, error) {
+	if f.controls != nil {
+		if _, ok := findControl(f.controls, control); !ok {
+			return false, fmt.Errorf("control %s not found", control)
+		}
+	}
 	return false, nil
 }
 
@@ -52,6 +132,9 @@ func (f *fakeMixer) SetMute(card uint, control string, muted bool) error {
 }
 
 func (f *fakeMixer) SetVolume(card uint, control string, values []int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.history = append(f.history, append([]int(nil), values...))
 	f.card = card
 	f.control = control
 	if values != nil {
@@ -63,6 +146,119 @@ func (f *fakeMixer) SetVolume(card uint, control string, values []int) error {
 	return f.err
 }
 
+// countingMixer decorates another mixer and counts ListControls calls.
+type countingMixer struct {
+	mixer
+	listControls int
+}
+
+func (c *countingMixer) ListControls(card uint) ([]alsa.Control, error) {
+	c.listControls++
+	return c.mixer.ListControls(card)
+}
+
+// newTestServer creates a Server, failing the test if it cannot be built.
+func newTestServer(t *testing.T, cfg *config.Config, hub *sse.Hub) *Server {
+	t.Helper()
+	srv, err := NewServer(cfg, hub)
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+	return srv
+}
+
+// subscribeEvents opens an SSE stream on baseURL and returns a channel
+// carrying the raw "event:" and "data:" lines as they arrive. It waits until
+// the hub has registered the subscriber before returning. The stream is
+// closed during test cleanup, so register the test server's Close with
+// t.Cleanup before subscribing.
+func subscribeEvents(t *testing.T, baseURL string, hub *sse.Hub) <-chan string {
+	t.Helper()
+	return subscribeEventsAs(t, baseURL, hub, "")
+}
+
+// subscribeEventsAs is subscribeEvents for a client identifying itself with
+// clientID.
+func subscribeEventsAs(t *testing.T, baseURL string, hub *sse.Hub, clientID string) <-chan string {
+	t.Helper()
+
+	query := url.Values{}
+	if clientID != "" {
+		query.Set("clientId", clientID)
+	}
+	return subscribeEventsWith(t, baseURL, hub, query)
+}
+
+// subscribeEventsWith is subscribeEvents with query parameters for /events.
+func subscribeEventsWith(t *testing.T, baseURL string, hub *sse.Hub, query url.Values) <-chan string {
+	t.Helper()
+
+	target := baseURL + "/events"
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	before := hub.ClientCount()
+	req, err := http.NewRequest(http.MethodGet, target, nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	req = req.WithContext(ctx)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+
+	lines := make(chan string, 100)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for hub.ClientCount() <= before {
+		if time.Now().After(deadline) {
+			t.Fatal("SSE client was not registered in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	return lines
+}
+
+// waitForEvent reads lines until an event of the given type arrives and
+// returns its data line.
+func waitForEvent(t *testing.T, lines <-chan string, eventType string, timeout time.Duration) string {
+	t.Helper()
+
+	timer := time.After(timeout)
+	found := false
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				t.Fatalf("SSE stream closed before %q arrived", eventType)
+			}
+			if line == "event: "+eventType {
+				found = true
+				continue
+			}
+			if found && strings.HasPrefix(line, "data: ") {
+				return strings.TrimPrefix(line, "data: ")
+			}
+		case <-timer:
+			t.Fatalf("timed out waiting for %q event", eventType)
+		}
+	}
+}
+
 func TestNewServer(t *testing.T) {
 	cfg := &config.Config{
 		Port:     0, // Use port 0 to let system assign a random port
@@ -70,7 +266,10 @@ func TestNewServer(t *testing.T) {
 	}
 	hub := sse.NewHub()
 
-	srv := NewServer(cfg, hub)
+	srv, err := NewServer(cfg, hub)
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
 
 	if srv == nil {
 		t.Fatal("NewServer returned nil")
@@ -93,13 +292,75 @@ func TestNewServer(t *testing.T) {
 	}
 }
 
+func TestNewServer_BrokenTemplate(t *testing.T) {
+	origTemplateFS := templateFS
+	templateFS = func() fs.FS {
+		return fstest.MapFS{
+			"base.html":     {Data: []byte(`{{ define "base" }}{{ .Theme `)},
+			"index.html":    {Data: []byte(`{{ define "content" }}{{ end }}`)},
+			"controls.html": {Data: []byte(`{{ define "controls" }}{{ end }}`)},
+			"notfound.html": {Data: []byte(`{{ define "notfound" }}{{ end }}`)},
+		}
+	}
+	defer func() {
+		templateFS = origTemplateFS
+	}()
+
+	cfg := &config.Config{
+		Port:     0,
+		BindAddr: "127.0.0.1",
+	}
+
+	srv, err := NewServer(cfg, sse.NewHub())
+	if err == nil {
+		t.Fatal("expected an error for a broken template")
+	}
+	if srv != nil {
+		t.Error("expected no server when templates fail to parse")
+	}
+	if !strings.Contains(err.Error(), "base.html") {
+		t.Errorf("expected error to name the broken template, got %v", err)
+	}
+}
+
+func TestNewServer_TemplateFieldMismatch(t *testing.T) {
+	origTemplateFS := templateFS
+	templateFS = func() fs.FS {
+		return fstest.MapFS{
+			"base.html":     {Data: []byte(`{{ define "base" }}{{ template "content" . }}{{ end }}`)},
+			"index.html":    {Data: []byte(`{{ define "content" }}{{ template "controls" . }}{{ end }}`)},
+			"controls.html": {Data: []byte(`{{ define "controls" }}{{ range .Cards }}{{ .Volume }}{{ end }}{{ end }}{{ define "control" }}{{ end }}`)},
+			"notfound.html": {Data: []byte(`{{ define "notfound" }}{{ end }}`)},
+		}
+	}
+	defer func() {
+		templateFS = origTemplateFS
+	}()
+
+	cfg := &config.Config{
+		Port:     0,
+		BindAddr: "127.0.0.1",
+	}
+
+	srv, err := NewServer(cfg, sse.NewHub())
+	if err == nil {
+		t.Fatal("expected an error for a template referencing a missing field")
+	}
+	if srv != nil {
+		t.Error("expected no server when templates do not match the page data")
+	}
+	if !strings.Contains(err.Error(), "Volume") {
+		t.Errorf("expected error to name the missing field, got %v", err)
+	}
+}
+
 func TestServerRoutes(t *testing.T) {
 	cfg := &config.Config{
 		Port:     0,
 		BindAddr: "127.0.0.1",
 	}
 	hub := sse.NewHub()
-	srv := NewServer(cfg, hub)
+	srv := newTestServer(t, cfg, hub)
 
 	// Create a test server
 	ts := &http.Server{
@@ -210,16 +471,10 @@ func TestVolumeHandler_Success(t *testing.T) {
 		BindAddr: "127.0.0.1",
 	}
 	hub := sse.NewHub()
-	srv := NewServer(cfg, hub)
+	srv := newTestServer(t, cfg, hub)
 
 	fm := &fakeMixer{}
-	origNewMixer := newMixer
-	newMixer = func() mixer {
-		return fm
-	}
-	defer func() {
-		newMixer = origNewMixer
-	}()
+	srv.useMixer(fm)
 
 	form := url.Values{}
 	form.Set("card", "0")
@@ -259,16 +514,10 @@ func TestVolumeHandler_InvalidControl(t *testing.T) {
 		BindAddr: "127.0.0.1",
 	}
 	hub := sse.NewHub()
-	srv := NewServer(cfg, hub)
+	srv := newTestServer(t, cfg, hub)
 
 	fm := &fakeMixer{}
-	origNewMixer := newMixer
-	newMixer = func() mixer {
-		return fm
-	}
-	defer func() {
-		newMixer = origNewMixer
-	}()
+	srv.useMixer(fm)
 
 	form := url.Values{}
 	form.Set("card", "0")
@@ -298,7 +547,7 @@ func TestVolumeHandler_VolumeClamping(t *testing.T) {
 		BindAddr: "127.0.0.1",
 	}
 	hub := sse.NewHub()
-	srv := NewServer(cfg, hub)
+	srv := newTestServer(t, cfg, hub)
 
 	tests := []struct {
 		name           string
@@ -315,13 +564,7 @@ func TestVolumeHandler_VolumeClamping(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			fm := &fakeMixer{}
-			origNewMixer := newMixer
-			newMixer = func() mixer {
-				return fm
-			}
-			defer func() {
-				newMixer = origNewMixer
-			}()
+			srv.useMixer(fm)
 
 			form := url.Values{}
 			form.Set("card", "0")
@@ -345,13 +588,68 @@ func TestVolumeHandler_VolumeClamping(t *testing.T) {
 	}
 }
 
+func TestVolumeHandlers_StrictVolume(t *testing.T) {
+	endpoints := []struct {
+		name string
+		post func(srv *Server, volume string) *httptest.ResponseRecorder
+	}{
+		{"control volume", func(srv *Server, volume string) *httptest.ResponseRecorder {
+			return postVolume(srv, volume, "")
+		}},
+		{"card control volume", func(srv *Server, volume string) *httptest.ResponseRecorder {
+			form := url.Values{"value": {volume}}
+			req := httptest.NewRequest(http.MethodPost, "/card/0/control/Master/volume", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			resp := httptest.NewRecorder()
+			srv.mux.ServeHTTP(resp, req)
+			return resp
+		}},
+	}
+
+	for _, strict := range []bool{false, true} {
+		for _, ep := range endpoints {
+			t.Run(fmt.Sprintf("%s strict=%t", ep.name, strict), func(t *testing.T) {
+				cfg := &config.Config{
+					Port:         0,
+					BindAddr:     "127.0.0.1",
+					StrictVolume: strict,
+				}
+				srv := newTestServer(t, cfg, sse.NewHub())
+				fm := &fakeMixer{}
+				srv.useMixer(fm)
+
+				resp := ep.post(srv, "150")
+
+				if strict {
+					if resp.Code != http.StatusBadRequest {
+						t.Fatalf("expected status %d, got %d", http.StatusBadRequest, resp.Code)
+					}
+					if !strings.Contains(resp.Body.String(), "out of range") {
+						t.Errorf("expected an out of range error, got %q", resp.Body.String())
+					}
+					if fm.called {
+						t.Error("expected SetVolume NOT to be called for a rejected volume")
+					}
+					return
+				}
+				if resp.Code != http.StatusNoContent {
+					t.Fatalf("expected status %d, got %d", http.StatusNoContent, resp.Code)
+				}
+				if len(fm.values) != 1 || fm.values[0] != 100 {
+					t.Errorf("expected volume clamped to 100, got %v", fm.values)
+				}
+			})
+		}
+	}
+}
+
 func TestServerCORSMiddleware(t *testing.T) {
 	cfg := &config.Config{
 		Port:     0,
 		BindAddr: "127.0.0.1",
 	}
 	hub := sse.NewHub()
-	srv := NewServer(cfg, hub)
+	srv := newTestServer(t, cfg, hub)
 
 	// Create a test server using the full handler chain with middleware
 	ts := &http.Server{
@@ -392,7 +690,7 @@ func TestServerStartAndStop(t *testing.T) {
 		BindAddr: "127.0.0.1",
 	}
 	hub := sse.NewHub()
-	srv := NewServer(cfg, hub)
+	srv := newTestServer(t, cfg, hub)
 
 	// Create a listener to get a random port
 	listener, err := net.Listen("tcp", "127.0.0.1:0")
@@ -449,3 +747,1055 @@ func TestServerStartAndStop(t *testing.T) {
 		t.Error("Server should not be accepting connections after stop")
 	}
 }
+
+func TestCardControlVolumeHandler_SpecialCharacterNames(t *testing.T) {
+	cfg := &config.Config{
+		Port:     0,
+		BindAddr: "127.0.0.1",
+	}
+	hub := sse.NewHub()
+	srv := newTestServer(t, cfg, hub)
+
+	tests := []struct {
+		name        string
+		path        string
+		body        url.Values
+		controlName string
+	}{
+		{
+			name:        "comma in path segment",
+			path:        "/card/0/control/" + url.PathEscape("Line,1") + "/volume",
+			body:        url.Values{"value": {"40"}},
+			controlName: "Line,1 Playback Volume",
+		},
+		{
+			name:        "escaped slash in path segment",
+			path:        "/card/0/control/Line%2FMic/volume",
+			body:        url.Values{"value": {"40"}},
+			controlName: "Line/Mic Playback Volume",
+		},
+		{
+			name:        "percent sign in path segment",
+			path:        "/card/0/control/100%25%20Boost/volume",
+			body:        url.Values{"value": {"40"}},
+			controlName: "100% Boost Playback Volume",
+		},
+		{
+			name:        "slash via query parameter",
+			path:        "/card/0/control/volume?control=" + url.QueryEscape("Line/Mic"),
+			body:        url.Values{"value": {"40"}},
+			controlName: "Line/Mic Playback Volume",
+		},
+		{
+			name:        "slash via form body",
+			path:        "/card/0/control/volume",
+			body:        url.Values{"value": {"40"}, "control": {"Line/Mic"}},
+			controlName: "Line/Mic Playback Volume",
+		},
+		{
+			name:        "path segment wins over parameter",
+			path:        "/card/0/control/" + url.PathEscape("Line,1") + "/volume?control=Master",
+			body:        url.Values{"value": {"40"}, "control": {"Master"}},
+			controlName: "Line,1 Playback Volume",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fm := &fakeMixer{controls: []alsa.Control{
+				{Name: tt.controlName, Type: "integer", Min: 0, Max: 100, Count: 2},
+			}}
+			srv.useMixer(fm)
+
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			resp := httptest.NewRecorder()
+			srv.mux.ServeHTTP(resp, req)
+
+			if resp.Code != http.StatusNoContent {
+				t.Fatalf("expected status %d, got %d: %s", http.StatusNoContent, resp.Code, resp.Body.String())
+			}
+			if fm.control != tt.controlName {
+				t.Errorf("expected control %q, got %q", tt.controlName, fm.control)
+			}
+		})
+	}
+
+	// A form that cannot be parsed is rejected rather than read as empty.
+	req := httptest.NewRequest(http.MethodPost, "/card/0/control/volume", strings.NewReader("control=%zz&value=40"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp := httptest.NewRecorder()
+	srv.mux.ServeHTTP(resp, req)
+	if resp.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d for a malformed form, got %d", http.StatusBadRequest, resp.Code)
+	}
+}
+
+func TestDebugControlsHandler_UnavailableReason(t *testing.T) {
+	cfg := &config.Config{
+		Port:     0,
+		BindAddr: "127.0.0.1",
+	}
+	hub := sse.NewHub()
+	srv := newTestServer(t, cfg, hub)
+
+	// Closing is a no-op on the stub platform, where the mixer is never open.
+	srv.mixer.Close()
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/controls", nil)
+	resp := httptest.NewRecorder()
+	srv.DebugControlsHandler(resp, req)
+
+	if resp.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status %d, got %d", http.StatusServiceUnavailable, resp.Code)
+	}
+	if reason := srv.mixer.UnavailableReason(); !strings.Contains(resp.Body.String(), reason) {
+		t.Errorf("expected body to explain %q, got %q", reason, resp.Body.String())
+	}
+
+	srv.mixer = nil
+	resp = httptest.NewRecorder()
+	srv.DebugControlsHandler(resp, req)
+
+	if !strings.Contains(resp.Body.String(), "failed to open") {
+		t.Errorf("expected body to explain the mixer failed to open, got %q", resp.Body.String())
+	}
+}
+
+func TestReadOnlyMode(t *testing.T) {
+	cfg := &config.Config{
+		Port:     0,
+		BindAddr: "127.0.0.1",
+		ReadOnly: true,
+	}
+	hub := sse.NewHub()
+	srv := newTestServer(t, cfg, hub)
+
+	fm := &fakeMixer{}
+	srv.useMixer(fm)
+
+	paths := []string{
+		"/control/volume",
+		"/control/mute",
+		"/control/capture",
+		"/card/0/control/Master/volume",
+		"/card/0/control/Master/mute",
+		"/card/0/control/Master/capture",
+		"/api/rescan",
+	}
+	for _, path := range paths {
+		form := url.Values{"card": {"0"}, "control": {"Master Playback Volume"}, "volume": {"10"}}
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		resp := httptest.NewRecorder()
+		srv.mux.ServeHTTP(resp, req)
+
+		if resp.Code != http.StatusForbidden {
+			t.Errorf("POST %s: expected status %d, got %d", path, http.StatusForbidden, resp.Code)
+		}
+	}
+	if fm.called {
+		t.Error("expected SetVolume NOT to be called in read-only mode")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	resp := httptest.NewRecorder()
+	srv.mux.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.Code)
+	}
+	if !strings.Contains(resp.Body.String(), "is-read-only") {
+		t.Error("expected page to render in read-only mode")
+	}
+}
+
+func TestResolveCardParam(t *testing.T) {
+	cards := []alsa.Card{
+		{ID: 0, Name: "PCH", LongName: "HDA Intel PCH at 0xf7f10000 irq 32"},
+		{ID: 1, Name: "Device", LongName: "C-Media USB Audio Device at usb-0000:00:14.0-1, full speed"},
+		// A card whose short name collides with another card's long name.
+		{ID: 2, Name: "C-Media USB Audio Device at usb-0000:00:14.0-1, full speed", LongName: "Other"},
+	}
+
+	tests := []struct {
+		param string
+		want  uint
+	}{
+		{"", 0},
+		{"default", 0},
+		{"2", 2},
+		{"7", 0},
+		{"Device", 1},
+		{"C-Media USB Audio Device at usb-0000:00:14.0-1, full speed", 1},
+		{"unknown", 0},
+	}
+
+	for _, tt := range tests {
+		if got := resolveCardParam(tt.param, cards, 0); got != tt.want {
+			t.Errorf("resolveCardParam(%q) = %d, want %d", tt.param, got, tt.want)
+		}
+	}
+}
+
+func TestCardSelectorShowsLongName(t *testing.T) {
+	cfg := &config.Config{
+		Port:     0,
+		BindAddr: "127.0.0.1",
+	}
+	srv := newTestServer(t, cfg, sse.NewHub())
+
+	data := pageData{
+		Theme: "linux-console",
+		AllCards: []alsa.Card{
+			{ID: 1, Name: "Device", LongName: "C-Media USB Audio Device at usb-0000:00:14.0-1, full speed"},
+			{ID: 2, Name: "Device_1", LongName: "C-Media USB Audio Device at usb-0000:00:14.0-2, full speed"},
+		},
+	}
+
+	var buf strings.Builder
+	if err := srv.tmpl.ExecuteTemplate(&buf, "base", data); err != nil {
+		t.Fatalf("failed to render page: %v", err)
+	}
+	out := buf.String()
+
+	for _, card := range data.AllCards {
+		if !strings.Contains(out, `title="`+card.LongName+`"`) {
+			t.Errorf("expected selector tooltip for %s to show %q", card.Name, card.LongName)
+		}
+		if !strings.Contains(out, card.Name+" — "+card.LongName) {
+			t.Errorf("expected selector label for %s to include its long name", card.Name)
+		}
+	}
+}
+
+func TestServerUsesDecoratedMixer(t *testing.T) {
+	cfg := &config.Config{
+		Port:     0,
+		BindAddr: "127.0.0.1",
+	}
+	srv := newTestServer(t, cfg, sse.NewHub())
+
+	wrapped := &countingMixer{mixer: &fakeMixer{}}
+	srv.mixer = wrapped
+
+	cards := srv.loadCardsForFilter(0, ViewModeAll)
+	if len(cards) != 1 || cards[0].Name != "Test Card" {
+		t.Fatalf("expected the fake mixer's card, got %+v", cards)
+	}
+	if len(cards[0].Controls) == 0 || cards[0].Controls[0].BaseName != "Master" {
+		t.Fatalf("expected the fake mixer's Master control, got %+v", cards[0].Controls)
+	}
+	if wrapped.listControls == 0 {
+		t.Error("expected ListControls to go through the decorator")
+	}
+}
+
+func TestServeIPv6(t *testing.T) {
+	if l, err := net.Listen("tcp6", "[::1]:0"); err != nil {
+		t.Skipf("IPv6 loopback not available: %v", err)
+	} else {
+		l.Close()
+	}
+
+	cfg := &config.Config{
+		Port:     0,
+		BindAddr: "::1",
+	}
+	srv := newTestServer(t, cfg, sse.NewHub())
+	if srv.server.Addr != "[::1]:0" {
+		t.Fatalf("expected IPv6 address to be bracketed, got %q", srv.server.Addr)
+	}
+
+	listeners, err := srv.listen()
+	if err != nil {
+		t.Fatalf("listen() error = %v", err)
+	}
+	if len(listeners) != 1 || listeners[0].Addr().Network() != "tcp" {
+		t.Fatalf("expected one TCP listener, got %v", listeners)
+	}
+	go srv.serve(listeners)
+	t.Cleanup(func() { srv.server.Close() })
+
+	resp, err := http.Get("http://" + listeners[0].Addr().String() + "/manifest.json")
+	if err != nil {
+		t.Fatalf("request over IPv6 failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestListenNetwork(t *testing.T) {
+	tests := map[string]string{
+		"0.0.0.0":   "tcp4",
+		"127.0.0.1": "tcp4",
+		"::":        "tcp6",
+		"::1":       "tcp6",
+		"localhost": "tcp",
+	}
+	for bind, want := range tests {
+		if got := listenNetwork(bind); got != want {
+			t.Errorf("listenNetwork(%q) = %q, want %q", bind, got, want)
+		}
+	}
+}
+
+func TestPageEmbedsShortcuts(t *testing.T) {
+	render := func(shortcuts map[string][]string) string {
+		cfg := &config.Config{
+			Port:      0,
+			BindAddr:  "127.0.0.1",
+			Shortcuts: shortcuts,
+		}
+		srv := newTestServer(t, cfg, sse.NewHub())
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		resp := httptest.NewRecorder()
+		srv.mux.ServeHTTP(resp, req)
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, resp.Code)
+		}
+		return resp.Body.String()
+	}
+
+	out := render(config.DefaultShortcuts)
+	for _, want := range []string{`"mute":["m"]`, `"volume-up":["ArrowUp","ArrowRight"]`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected default shortcut map to contain %s", want)
+		}
+	}
+
+	out = render(map[string][]string{"mute": {"x"}})
+	if !strings.Contains(out, `"mute":["x"]`) {
+		t.Errorf("expected configured mute shortcut in page")
+	}
+	if strings.Contains(out, `"mute":["m"]`) {
+		t.Errorf("expected configured shortcut to replace the default")
+	}
+}
+
+func TestVolumeHandler_BroadcastsReadBackValue(t *testing.T) {
+	cfg := &config.Config{
+		Port:     0,
+		BindAddr: "127.0.0.1",
+	}
+	hub := sse.NewHub()
+	go hub.Run()
+	srv := newTestServer(t, cfg, hub)
+
+	// amixer "succeeds" but the control only accepts coarse steps, so the
+	// hardware ends up at 40% rather than the requested 55%.
+	fm := &fakeMixer{readBack: []int{40, 40}}
+	srv.useMixer(fm)
+
+	ts := httptest.NewServer(srv.mux)
+	t.Cleanup(ts.Close)
+	events := subscribeEvents(t, ts.URL, hub)
+
+	form := url.Values{}
+	form.Set("card", "0")
+	form.Set("control", "Master Playback Volume")
+	form.Set("volume", "55")
+	resp, err := http.PostForm(ts.URL+"/control/volume", form)
+	if err != nil {
+		t.Fatalf("POST /control/volume: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected status %d, got %d", http.StatusNoContent, resp.StatusCode)
+	}
+
+	data := waitForEvent(t, events, "mixer-update", time.Second)
+	var payload struct {
+		State map[string]map[string]struct {
+			Volume []int
+		} `json:"state"`
+	}
+	if err := json.Unmarshal([]byte(data), &payload); err != nil {
+		t.Fatalf("decoding event data %q: %v", data, err)
+	}
+	got := payload.State["0"]["Master Playback Volume"].Volume
+	if len(got) != 1 || got[0] != 40 {
+		t.Errorf("expected broadcast of the read-back volume [40], got %v", got)
+	}
+}
+
+func TestFilterExposedCards(t *testing.T) {
+	cards := []alsa.Card{
+		{ID: 0, Name: "PCH", LongName: "HDA Intel PCH at 0xf7f10000 irq 32"},
+		{ID: 1, Name: "HDMI", LongName: "HDA ATI HDMI at 0xf7e60000 irq 33"},
+		{ID: 2, Name: "Device", LongName: "C-Media USB Audio Device at usb-0000:00:14.0-1, full speed"},
+	}
+
+	tests := []struct {
+		name   string
+		expose []string
+		want   []uint
+	}{
+		{"unset exposes all", nil, []uint{0, 1, 2}},
+		{"by index", []string{"2"}, []uint{2}},
+		{"by name", []string{"PCH", "Device"}, []uint{0, 2}},
+		{"by long name", []string{"HDA ATI HDMI at 0xf7e60000 irq 33"}, []uint{1}},
+		{"no match", []string{"Loopback"}, []uint{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := filterExposedCards(cards, tt.expose)
+			ids := make([]uint, 0, len(got))
+			for _, c := range got {
+				ids = append(ids, c.ID)
+			}
+			if fmt.Sprint(ids) != fmt.Sprint(tt.want) {
+				t.Errorf("filterExposedCards(%v) = %v, want %v", tt.expose, ids, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequireExposedCard(t *testing.T) {
+	cfg := &config.Config{
+		Port:        0,
+		BindAddr:    "127.0.0.1",
+		ExposeCards: []string{"PCH"},
+	}
+	hub := sse.NewHub()
+	srv := newTestServer(t, cfg, hub)
+
+	fm := &fakeMixer{}
+	srv.useMixer(fm)
+
+	// No real card named PCH exists here, so card 1 is hidden.
+	form := url.Values{}
+	form.Set("volume", "50")
+	req := httptest.NewRequest(http.MethodPost, "/card/1/control/Master/volume", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp := httptest.NewRecorder()
+	srv.mux.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusNotFound {
+		t.Errorf("expected status %d for a hidden card, got %d", http.StatusNotFound, resp.Code)
+	}
+	if fm.called {
+		t.Error("expected mixer not to be touched for a hidden card")
+	}
+}
+
+func TestWantsJSON(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   bool
+	}{
+		{"", false},
+		{"*/*", false},
+		{"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", false},
+		{"application/json", true},
+		{"application/json, text/plain, */*", true},
+		{"text/html, application/json;q=0.5", false},
+		{"application/json;q=0.9, text/html;q=0.5", true},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept", tt.accept)
+		if got := wantsJSON(req); got != tt.want {
+			t.Errorf("wantsJSON(%q) = %v, want %v", tt.accept, got, tt.want)
+		}
+	}
+}
+
+func TestRootContentNegotiation(t *testing.T) {
+	cfg := &config.Config{
+		Port:     0,
+		BindAddr: "127.0.0.1",
+	}
+	srv := newTestServer(t, cfg, sse.NewHub())
+	if srv.monitor == nil {
+		t.Skip("ALSA monitor not available on this platform")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", "application/json")
+	resp := httptest.NewRecorder()
+	srv.mux.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.Code)
+	}
+	if ct := resp.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected Content-Type application/json, got %q", ct)
+	}
+	var body map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("expected a JSON body: %v", err)
+	}
+	if _, ok := body["state"]; !ok {
+		t.Errorf("expected the state payload, got %v", body)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", "*/*")
+	resp = httptest.NewRecorder()
+	srv.mux.ServeHTTP(resp, req)
+
+	if ct := resp.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+		t.Errorf("expected HTML for */*, got %q", ct)
+	}
+}
+
+func TestNotFoundHandler(t *testing.T) {
+	cfg := &config.Config{
+		Port:     0,
+		BindAddr: "127.0.0.1",
+	}
+	srv := newTestServer(t, cfg, sse.NewHub())
+
+	t.Run("json", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/no/such/route", nil)
+		req.Header.Set("Accept", "application/json")
+		resp := httptest.NewRecorder()
+		srv.mux.ServeHTTP(resp, req)
+
+		if resp.Code != http.StatusNotFound {
+			t.Fatalf("expected status %d, got %d", http.StatusNotFound, resp.Code)
+		}
+		if ct := resp.Header().Get("Content-Type"); ct != "application/json" {
+			t.Errorf("expected Content-Type application/json, got %q", ct)
+		}
+		var body map[string]string
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			t.Fatalf("expected a JSON body: %v", err)
+		}
+		if body["error"] != "not found" || body["path"] != "/no/such/route" {
+			t.Errorf("unexpected body %v", body)
+		}
+	})
+
+	t.Run("html", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/no/such/page?theme=muji", nil)
+		req.Header.Set("Accept", "text/html,application/xhtml+xml,*/*;q=0.8")
+		resp := httptest.NewRecorder()
+		srv.mux.ServeHTTP(resp, req)
+
+		if resp.Code != http.StatusNotFound {
+			t.Fatalf("expected status %d, got %d", http.StatusNotFound, resp.Code)
+		}
+		if ct := resp.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+			t.Errorf("expected HTML, got %q", ct)
+		}
+		body := resp.Body.String()
+		for _, want := range []string{"theme-muji", "/static/themes/muji.css", "/no/such/page", "Page not found"} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected the 404 page to contain %q, got:\n%s", want, body)
+			}
+		}
+	})
+
+	t.Run("plain", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/no/such/route", nil)
+		resp := httptest.NewRecorder()
+		srv.mux.ServeHTTP(resp, req)
+
+		if resp.Code != http.StatusNotFound {
+			t.Fatalf("expected status %d, got %d", http.StatusNotFound, resp.Code)
+		}
+		if ct := resp.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+			t.Errorf("expected the plain default, got %q", ct)
+		}
+	})
+}
+
+func TestGroupControls(t *testing.T) {
+	controls := []controlView{
+		{Name: "Mic Capture Volume", View: "capture"},
+		{Name: "Master Playback Volume", View: "playback"},
+		{Name: "PCM Playback Volume", View: "playback"},
+	}
+
+	groups := groupControls(controls)
+	if len(groups) != 2 {
+		t.Fatalf("expected 2 groups, got %d", len(groups))
+	}
+	if groups[0].Title != "Playback" || len(groups[0].Controls) != 2 {
+		t.Errorf("expected Playback group first with 2 controls, got %+v", groups[0])
+	}
+	if groups[1].Title != "Capture" || len(groups[1].Controls) != 1 || groups[1].Controls[0].Name != "Mic Capture Volume" {
+		t.Errorf("expected Capture group with the mic control, got %+v", groups[1])
+	}
+
+	if groups := groupControls(controls[1:]); len(groups) != 1 || groups[0].View != "playback" {
+		t.Errorf("expected empty Capture group to be omitted, got %+v", groups)
+	}
+}
+
+func TestControlNamesAreCaseInsensitive(t *testing.T) {
+	cfg := &config.Config{
+		Port:     0,
+		BindAddr: "127.0.0.1",
+	}
+	srv := newTestServer(t, cfg, sse.NewHub())
+
+	fm := &fakeMixer{}
+	srv.useMixer(fm)
+
+	form := url.Values{}
+	form.Set("card", "0")
+	form.Set("control", "master playback volume")
+	form.Set("volume", "40")
+	req := httptest.NewRequest(http.MethodPost, "/control/volume", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp := httptest.NewRecorder()
+	srv.mux.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusNoContent {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusNoContent, resp.Code, resp.Body.String())
+	}
+	if fm.control != "Master Playback Volume" {
+		t.Errorf("expected the canonical control name, got %q", fm.control)
+	}
+
+	fm.control = ""
+	req = httptest.NewRequest(http.MethodPost, "/card/0/control/MASTER/volume", strings.NewReader("volume=60"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp = httptest.NewRecorder()
+	srv.mux.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusNoContent {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusNoContent, resp.Code, resp.Body.String())
+	}
+	if fm.control != "Master Playback Volume" {
+		t.Errorf("expected base name MASTER to resolve to Master Playback Volume, got %q", fm.control)
+	}
+}
+
+func TestVolumeStepHandler(t *testing.T) {
+	cfg := &config.Config{
+		Port:     0,
+		BindAddr: "127.0.0.1",
+	}
+	srv := newTestServer(t, cfg, sse.NewHub())
+
+	fm := &fakeMixer{
+		controls: []alsa.Control{
+			{Name: "Digital Playback Volume", Type: "integer", Min: 0, Max: 255, Count: 2},
+		},
+		raw: []int{100, 100},
+	}
+	srv.useMixer(fm)
+
+	step := func(direction string) (int, []int) {
+		form := url.Values{}
+		form.Set("card", "0")
+		form.Set("control", "Digital Playback Volume")
+		form.Set("direction", direction)
+		req := httptest.NewRequest(http.MethodPost, "/control/volume/step", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		resp := httptest.NewRecorder()
+		srv.VolumeStepHandler(resp, req)
+
+		var body struct {
+			Raw []int `json:"raw"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&body)
+		return resp.Code, body.Raw
+	}
+
+	if code, raw := step("up"); code != http.StatusOK || len(raw) != 2 || raw[0] != 101 || raw[1] != 101 {
+		t.Fatalf("expected one step up to give raw [101 101], got %d %v", code, raw)
+	}
+	if code, raw := step("down"); code != http.StatusOK || raw[0] != 100 {
+		t.Fatalf("expected one step down to give raw 100, got %d %v", code, raw)
+	}
+
+	fm.raw = []int{255, 255}
+	if _, raw := step("up"); raw[0] != 255 {
+		t.Errorf("expected a step past the maximum to clamp at 255, got %v", raw)
+	}
+
+	if code, _ := step("sideways"); code != http.StatusBadRequest {
+		t.Errorf("expected status %d for an invalid direction, got %d", http.StatusBadRequest, code)
+	}
+}
+
+func TestVolumeHandler_ChannelCount(t *testing.T) {
+	cfg := &config.Config{
+		Port:     0,
+		BindAddr: "127.0.0.1",
+	}
+	srv := newTestServer(t, cfg, sse.NewHub())
+
+	// fakeMixer's Master Playback Volume has 2 channels.
+	fm := &fakeMixer{}
+	srv.useMixer(fm)
+
+	if resp := postVolume(srv, "10,20,30", ""); resp.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d for 3 values on a 2-channel control, got %d", http.StatusBadRequest, resp.Code)
+	}
+	if fm.called {
+		t.Fatal("expected SetVolume not to be called for a mismatched channel count")
+	}
+
+	if resp := postVolume(srv, "10,20", ""); resp.Code != http.StatusNoContent {
+		t.Fatalf("expected status %d for one value per channel, got %d", http.StatusNoContent, resp.Code)
+	}
+	if len(fm.values) != 2 || fm.values[0] != 10 || fm.values[1] != 20 {
+		t.Errorf("expected per-channel values [10 20], got %v", fm.values)
+	}
+
+	if resp := postVolume(srv, "40", ""); resp.Code != http.StatusNoContent {
+		t.Fatalf("expected status %d for a single value, got %d", http.StatusNoContent, resp.Code)
+	}
+}
+
+func TestSwitchlessControlRendersNoMute(t *testing.T) {
+	cfg := &config.Config{
+		Port:     0,
+		BindAddr: "127.0.0.1",
+	}
+	srv := newTestServer(t, cfg, sse.NewHub())
+	srv.mixer = &fakeMixer{controls: []alsa.Control{
+		{Name: "Speaker Playback Volume", Type: "integer", Min: 0, Max: 255, Step: 1, Count: 2},
+		{Name: "Master Playback Volume", Type: "integer", Min: 0, Max: 100, Step: 1, Count: 2},
+		{Name: "Master Playback Switch", Type: "boolean"},
+	}}
+
+	cards := srv.loadCardsForFilter(0, ViewModeAll)
+	if len(cards) != 1 {
+		t.Fatalf("expected one card, got %+v", cards)
+	}
+	states := make(map[string]MuteState)
+	for _, ctrl := range cards[0].Controls {
+		states[ctrl.BaseName] = ctrl.MuteState
+	}
+	if states["Speaker"] != MuteNone {
+		t.Errorf("expected Speaker mute state %q, got %q", MuteNone, states["Speaker"])
+	}
+	if states["Master"] != MuteUnmuted {
+		t.Errorf("expected Master mute state %q, got %q", MuteUnmuted, states["Master"])
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	resp := httptest.NewRecorder()
+	srv.mux.ServeHTTP(resp, req)
+	body := resp.Body.String()
+	if got := strings.Count(body, `data-mute-state="none"`); got != 1 {
+		t.Errorf("expected exactly one \"no mute\" indicator, got %d. Output: %s", got, body)
+	}
+	if got := strings.Count(body, "mixer-control__toggle--mute"); got != 1 {
+		t.Errorf("expected exactly one mute toggle, got %d", got)
+	}
+}
+
+// switchlessMixer is a fakeMixer whose controls have volumes but no
+// switches, and whose GetMute fails as it does on a non-boolean control.
+type switchlessMixer struct {
+	*fakeMixer
+}
+
+func (m switchlessMixer) HasPlaybackSwitch(card uint, control string) (bool, error) {
+	return false, nil
+}
+
+func (m switchlessMixer) HasCaptureSwitch(card uint, control string) (bool, error) {
+	return false, nil
+}
+
+func (m switchlessMixer) Supports(card uint, control string, op alsa.Operation) (bool, error) {
+	return alsa.SupportsOperation(m, card, control, op)
+}
+
+func (m switchlessMixer) GetMute(card uint, control string) (bool, error) {
+	return false, fmt.Errorf("control %s is not a switch", control)
+}
+
+func TestMuteUnsupportedControl(t *testing.T) {
+	cfg := &config.Config{
+		Port:     0,
+		BindAddr: "127.0.0.1",
+	}
+	srv := newTestServer(t, cfg, sse.NewHub())
+	srv.useMixer(switchlessMixer{&fakeMixer{controls: []alsa.Control{
+		{Name: "Speaker Playback Volume", Type: "integer", Min: 0, Max: 255, Step: 1, Count: 2},
+	}}})
+
+	tests := []struct {
+		name string
+		path string
+		form url.Values
+		want string
+	}{
+		{"mute", "/control/mute", url.Values{"card": {"0"}, "control": {"Speaker Playback Volume"}}, "does not support mute"},
+		{"card mute", "/card/0/control/Speaker/mute", url.Values{}, "does not support mute"},
+		{"capture", "/control/capture", url.Values{"card": {"0"}, "control": {"Speaker Playback Volume"}}, "does not support capture"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			resp := httptest.NewRecorder()
+			srv.mux.ServeHTTP(resp, req)
+
+			if resp.Code != http.StatusBadRequest {
+				t.Fatalf("expected status %d, got %d: %s", http.StatusBadRequest, resp.Code, resp.Body.String())
+			}
+			if body := resp.Body.String(); !strings.Contains(body, tt.want) {
+				t.Errorf("expected %q in the error, got %q", tt.want, body)
+			}
+		})
+	}
+}
+
+func TestCardFlagSelectsPageDefault(t *testing.T) {
+	t.Setenv("ALSA_CARD", "1")
+	cfg := &config.Config{
+		Port:      0,
+		BindAddr:  "127.0.0.1",
+		CardIndex: 2,
+		CardSet:   true,
+	}
+	srv := newTestServer(t, cfg, sse.NewHub())
+	srv.mixer = &fakeMixer{cards: []alsa.Card{
+		{ID: 0, Name: "PCH"},
+		{ID: 1, Name: "HDMI"},
+		{ID: 2, Name: "USB"},
+	}}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	resp := httptest.NewRecorder()
+	srv.mux.ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.Code)
+	}
+
+	body := resp.Body.String()
+	if !strings.Contains(body, `<input type="hidden" name="card" value="2">`) {
+		t.Errorf("expected --card 2 to select card 2 on load. Output: %s", body)
+	}
+
+	// Without --card the ALSA default applies
+	srv.config.CardSet = false
+	resp = httptest.NewRecorder()
+	srv.mux.ServeHTTP(resp, req)
+	if !strings.Contains(resp.Body.String(), `<input type="hidden" name="card" value="1">`) {
+		t.Error("expected ALSA_CARD to select the default without --card")
+	}
+}
+
+func TestBroadcastDedupe(t *testing.T) {
+	cfg := &config.Config{
+		Port:         0,
+		BindAddr:     "127.0.0.1",
+		DedupeWindow: time.Minute,
+	}
+	hub := sse.NewHub()
+	go hub.Run()
+	srv := newTestServer(t, cfg, hub)
+
+	fm := &fakeMixer{}
+	srv.useMixer(fm)
+
+	ts := httptest.NewServer(srv.mux)
+	t.Cleanup(ts.Close)
+	events := subscribeEvents(t, ts.URL, hub)
+
+	// The same value twice, then a different one
+	for _, volume := range []int{75, 75, 60} {
+		fm.readBack = []int{volume, volume}
+		if resp := postVolume(srv, strconv.Itoa(volume), ""); resp.Code != http.StatusNoContent {
+			t.Fatalf("expected status %d, got %d", http.StatusNoContent, resp.Code)
+		}
+	}
+
+	var volumes []int
+	for range 2 {
+		data := waitForEvent(t, events, "mixer-update", time.Second)
+		var payload struct {
+			State map[string]map[string]struct {
+				Volume []int
+			} `json:"state"`
+		}
+		if err := json.Unmarshal([]byte(data), &payload); err != nil {
+			t.Fatalf("decoding event data %q: %v", data, err)
+		}
+		volumes = append(volumes, payload.State["0"]["Master Playback Volume"].Volume...)
+	}
+	select {
+	case line := <-events:
+		if line == "event: mixer-update" {
+			t.Fatalf("expected the repeated value to be broadcast once, got a third update after %v", volumes)
+		}
+	case <-time.After(200 * time.Millisecond):
+	}
+	slices.Sort(volumes)
+	if !slices.Equal(volumes, []int{60, 75}) {
+		t.Errorf("expected broadcasts of 75 and 60, got %v", volumes)
+	}
+}
+
+func TestBroadcastDedupeForgetsMonitorChanges(t *testing.T) {
+	var d broadcastDedupe
+	now := time.Now()
+	if d.duplicate(0, "Master Playback Volume", 75, false, time.Minute, now) {
+		t.Fatal("expected the first broadcast to go out")
+	}
+	if !d.duplicate(0, "Master Playback Volume", 75, false, time.Minute, now) {
+		t.Fatal("expected an immediate repeat to be dropped")
+	}
+
+	// Something else moved the control, so setting it back is news.
+	d.forgetSnapshot(&alsa.StateSnapshot{Cards: map[uint]alsa.CardState{
+		0: {Controls: map[string]alsa.ControlState{"Master Playback Volume": {Volume: []int{40}}}},
+	}})
+	if d.duplicate(0, "Master Playback Volume", 75, false, time.Minute, now) {
+		t.Error("expected the value to be broadcast again after a monitor change")
+	}
+}
+
+func TestControlKind(t *testing.T) {
+	tests := []struct {
+		ctrl alsa.Control
+		want ControlKind
+	}{
+		{alsa.Control{Name: "Channel Mode Playback Volume", Min: 0, Max: 2}, ControlKindStepper},
+		{alsa.Control{Name: "Mic Boost Volume", Min: 0, Max: 3}, ControlKindStepper},
+		{alsa.Control{Name: "Master Playback Volume", Min: 0, Max: 87}, ControlKindVolume},
+		{alsa.Control{Name: "Headphone Playback Volume", Min: 0, Max: 1}, ControlKindVolume},
+		{alsa.Control{Name: "Capture Volume", Min: 0, Max: 3}, ControlKindVolume},
+	}
+	for _, tt := range tests {
+		if got := controlKind(tt.ctrl); got != tt.want {
+			t.Errorf("controlKind(%s %d-%d) = %s, want %s", tt.ctrl.Name, tt.ctrl.Min, tt.ctrl.Max, got, tt.want)
+		}
+	}
+}
+
+func TestPrimaryControl(t *testing.T) {
+	volume := func(name string) alsa.Control {
+		return alsa.Control{Name: name, Type: "integer", Min: 0, Max: 87}
+	}
+	tests := []struct {
+		name     string
+		controls []alsa.Control
+		want     string
+	}{
+		{
+			name: "HDA Intel",
+			controls: []alsa.Control{
+				volume("Headphone Playback Volume"),
+				{Name: "Master Playback Switch", Type: "boolean"},
+				volume("Master Playback Volume"),
+				volume("PCM Playback Volume"),
+			},
+			want: "Master Playback Volume",
+		},
+		{
+			name: "USB DAC",
+			controls: []alsa.Control{
+				{Name: "PCM Playback Switch", Type: "boolean"},
+				volume("PCM Playback Volume"),
+				volume("Mic Capture Volume"),
+			},
+			want: "PCM Playback Volume",
+		},
+		{
+			name: "HDMI headset",
+			controls: []alsa.Control{
+				{Name: "IEC958 Playback Switch", Type: "boolean"},
+				volume("Headset Playback Volume"),
+				volume("Sidetone Playback Volume"),
+			},
+			want: "Headset Playback Volume",
+		},
+		{
+			name:     "switches only",
+			controls: []alsa.Control{{Name: "IEC958 Playback Switch", Type: "boolean"}},
+			want:     "",
+		},
+	}
+	for _, tt := range tests {
+		if got := primaryControl(tt.controls); got != tt.want {
+			t.Errorf("%s: primaryControl() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIndexControlRendersAsStepper(t *testing.T) {
+	cfg := &config.Config{
+		Port:     0,
+		BindAddr: "127.0.0.1",
+	}
+	srv := newTestServer(t, cfg, sse.NewHub())
+	srv.mixer = &fakeMixer{
+		raw: []int{1},
+		controls: []alsa.Control{
+			{Name: "Channel Mode Playback Volume", Type: "integer", Min: 0, Max: 2, Step: 1, Count: 1},
+			{Name: "Master Playback Volume", Type: "integer", Min: 0, Max: 100, Step: 1, Count: 2},
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	resp := httptest.NewRecorder()
+	srv.mux.ServeHTTP(resp, req)
+	body := resp.Body.String()
+
+	if strings.Contains(body, `id="volume-0-0-channel-mode-playback-volume"`) {
+		t.Error("expected the 0-2 control not to be rendered as a percentage slider")
+	}
+	for _, want := range []string{
+		`id="stepper-0-0-channel-mode-playback-volume"`,
+		`role="spinbutton"`,
+		`aria-valuemax="2"`,
+		`aria-valuenow="1"`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected stepper markup %s. Output: %s", want, body)
+		}
+	}
+	if !strings.Contains(body, `id="volume-0-0-master-playback-volume"`) {
+		t.Error("expected Master to remain a slider")
+	}
+	if !strings.Contains(body, `mixer-control mixer-control--primary" id="control-0-0-master-playback-volume"`) {
+		t.Error("expected Master to be marked as the card's primary control")
+	}
+}
+
+func TestReadsAndWritesShareOneMixer(t *testing.T) {
+	// In dry-run mode only the mixer the write went through knows the new
+	// value, so the page and the monitor seeing it shows they read through
+	// that same mixer.
+	cfg := &config.Config{
+		Port:     0,
+		BindAddr: "127.0.0.1",
+		DryRun:   true,
+	}
+	hub := sse.NewHub()
+	go hub.Run()
+	srv := newTestServer(t, cfg, hub)
+	fm := &fakeMixer{}
+	srv.useMixer(fm)
+
+	if resp := postVolume(srv, "40", ""); resp.Code != http.StatusNoContent {
+		t.Fatalf("expected status %d, got %d", http.StatusNoContent, resp.Code)
+	}
+	if fm.called {
+		t.Fatal("expected the dry-run write not to reach the hardware")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	resp := httptest.NewRecorder()
+	srv.mux.ServeHTTP(resp, req)
+	if body := resp.Body.String(); !strings.Contains(body, `aria-valuenow="40"`) {
+		t.Errorf("expected the page to read back the written 40%%. Output: %s", body)
+	}
+
+	monitor := alsa.NewMonitor(srv.mixer, hub, "")
+	t.Cleanup(monitor.Stop)
+	monitor.Rescan()
+	state := monitor.StateSince(time.Time{})
+	if got := state.Cards[0].Controls["Master Playback Volume"].Volume; len(got) == 0 || got[0] != 40 {
+		t.Errorf("expected the monitor to read back the written 40%%, got %v", got)
+	}
+}
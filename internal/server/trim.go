@@ -0,0 +1,43 @@
+package server
+
+// trimMixer wraps a mixer for --card-trim: a card's trim, in percentage
+// points, is added to every volume set on it and subtracted from every
+// volume read, so the UI's percentages are on the trimmed scale. Raw
+// volumes and dB levels are left alone.
+type trimMixer struct {
+	mixer
+	trims map[uint]int
+}
+
+// withTrims wraps m in a trimMixer, or returns m as is if no card has a
+// trim.
+func withTrims(m mixer, trims map[uint]int) mixer {
+	if len(trims) == 0 {
+		return m
+	}
+	return trimMixer{mixer: m, trims: trims}
+}
+
+// shift adds offset to each value, clamped to 0-100.
+func shift(values []int, offset int) []int {
+	if values == nil || offset == 0 {
+		return values
+	}
+	shifted := make([]int, len(values))
+	for i, v := range values {
+		shifted[i] = min(max(v+offset, 0), 100)
+	}
+	return shifted
+}
+
+func (t trimMixer) SetVolume(card uint, control string, values []int) error {
+	return t.mixer.SetVolume(card, control, shift(values, t.trims[card]))
+}
+
+func (t trimMixer) GetVolume(card uint, control string) ([]int, error) {
+	values, err := t.mixer.GetVolume(card, control)
+	if err != nil {
+		return nil, err
+	}
+	return shift(values, -t.trims[card]), nil
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/alsamixer-web/internal/alsa"
+	"github.com/user/alsamixer-web/internal/config"
+	"github.com/user/alsamixer-web/internal/sse"
+)
+
+func TestCardTrimShiftsVolumes(t *testing.T) {
+	cfg := &config.Config{
+		Port:      0,
+		BindAddr:  "127.0.0.1",
+		CardTrims: map[uint]int{0: 10},
+	}
+	srv := newTestServer(t, cfg, sse.NewHub())
+
+	fm := &fakeMixer{readBack: []int{60}}
+	srv.useMixer(fm)
+
+	m := srv.mixer
+	if err := m.SetVolume(0, "Master Playback Volume", []int{50}); err != nil {
+		t.Fatalf("SetVolume() error = %v", err)
+	}
+	if len(fm.values) != 1 || fm.values[0] != 60 {
+		t.Fatalf("expected UI 50%% to set the card to 60%%, got %v", fm.values)
+	}
+	got, err := m.GetVolume(0, "Master Playback Volume")
+	if err != nil {
+		t.Fatalf("GetVolume() error = %v", err)
+	}
+	if len(got) != 1 || got[0] != 50 {
+		t.Errorf("expected the card's 60%% to read as 50%%, got %v", got)
+	}
+
+	// Cards without a trim are untouched, and trimmed values stay in range.
+	if err := m.SetVolume(1, "Master Playback Volume", []int{50}); err != nil {
+		t.Fatalf("SetVolume() error = %v", err)
+	}
+	if fm.values[0] != 50 {
+		t.Errorf("expected an untrimmed card to get 50%%, got %v", fm.values)
+	}
+	if err := m.SetVolume(0, "Master Playback Volume", []int{95}); err != nil {
+		t.Fatalf("SetVolume() error = %v", err)
+	}
+	if fm.values[0] != 100 {
+		t.Errorf("expected a trimmed volume to be clamped to 100%%, got %v", fm.values)
+	}
+}
+
+func TestVolumeStepOnTrimmedCard(t *testing.T) {
+	cfg := &config.Config{
+		Port:      0,
+		BindAddr:  "127.0.0.1",
+		CardTrims: map[uint]int{0: 10},
+	}
+	hub := sse.NewHub()
+	go hub.Run()
+	srv := newTestServer(t, cfg, hub)
+
+	// After the step the hardware reads 80%, 70% on the trimmed scale.
+	// Converting raw 201 of 255 directly would give an untrimmed 78%.
+	fm := &fakeMixer{
+		controls: []alsa.Control{
+			{Name: "Digital Playback Volume", Type: "integer", Min: 0, Max: 255, Count: 1},
+		},
+		raw:      []int{200},
+		readBack: []int{80},
+	}
+	srv.useMixer(fm)
+
+	ts := httptest.NewServer(srv.mux)
+	t.Cleanup(ts.Close)
+	events := subscribeEvents(t, ts.URL, hub)
+
+	form := url.Values{}
+	form.Set("card", "0")
+	form.Set("control", "Digital Playback Volume")
+	form.Set("direction", "up")
+	resp, err := http.PostForm(ts.URL+"/control/volume/step", form)
+	if err != nil {
+		t.Fatalf("POST /control/volume/step: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Volume int `json:"volume"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if body.Volume != 70 {
+		t.Errorf("expected the step to report 70%% on the trimmed scale, got %d", body.Volume)
+	}
+
+	data := waitForEvent(t, events, "mixer-update", time.Second)
+	if !strings.Contains(data, `"Volume":[70]`) {
+		t.Errorf("expected the broadcast to carry 70%%, got %s", data)
+	}
+}
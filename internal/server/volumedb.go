@@ -0,0 +1,106 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// VolumeDBRelativeHandler handles POST /control/volume/db-relative
+// requests. It moves every channel of a control by delta_db decibels from
+// its current level, clamped to the control's dB range, and broadcasts
+// the resulting dB and percentage.
+//
+// dB are hardware levels, so --card-trim does not apply to them.
+func (s *Server) VolumeDBRelativeHandler(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	cardStr := r.Form.Get("card")
+	control := r.Form.Get("control")
+	deltaStr := r.Form.Get("delta_db")
+	if cardStr == "" || control == "" || deltaStr == "" {
+		http.Error(w, "missing card, control or delta_db", http.StatusBadRequest)
+		return
+	}
+
+	cardValue, err := strconv.ParseUint(cardStr, 10, 0)
+	if err != nil {
+		http.Error(w, "invalid card", http.StatusBadRequest)
+		return
+	}
+	cardID := uint(cardValue)
+
+	delta, err := strconv.ParseFloat(deltaStr, 64)
+	if err != nil || math.IsNaN(delta) || math.IsInf(delta, 0) {
+		http.Error(w, "invalid delta_db", http.StatusBadRequest)
+		return
+	}
+
+	m := s.mixer
+	controls, err := m.ListControls(cardID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to list controls: %v", err), http.StatusInternalServerError)
+		return
+	}
+	ctrl, found := findControl(controls, control)
+	if !found {
+		http.Error(w, "control not found", http.StatusBadRequest)
+		return
+	}
+	if ctrl.Type != "integer" {
+		http.Error(w, "control has no volume", http.StatusBadRequest)
+		return
+	}
+
+	minDB, maxDB, err := m.VolumeDBRange(cardID, ctrl.Name)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("control has no dB scale: %v", err), http.StatusBadRequest)
+		return
+	}
+	current, err := m.GetVolumeDB(cardID, ctrl.Name)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to get volume: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	db := make([]float64, len(current))
+	for i, v := range current {
+		db[i] = max(minDB, min(maxDB, v+delta))
+	}
+
+	s.ramps.cancel(rampKey(cardID, ctrl.Name))
+	if err := m.SetVolumeDB(cardID, ctrl.Name, db); err != nil {
+		http.Error(w, fmt.Sprintf("failed to set volume: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if actual, err := m.GetVolumeDB(cardID, ctrl.Name); err == nil && len(actual) > 0 {
+		db = actual
+	}
+
+	volume := 0
+	if volumes, err := m.GetVolume(cardID, ctrl.Name); err == nil && len(volumes) > 0 {
+		volume = volumes[0]
+	}
+
+	log.Printf("[POST /control/volume/db-relative] card=%d control=%s delta=%+.2fdB db=%v volume=%d", cardID, ctrl.Name, delta, db, volume)
+
+	if s.hub != nil {
+		muted, _ := m.GetMute(cardID, strings.Replace(ctrl.Name, " Volume", " Switch", 1))
+		s.broadcastControlDB(cardID, ctrl.Name, volume, muted, db)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"card":    cardID,
+		"control": ctrl.Name,
+		"db":      db,
+		"volume":  volume,
+	})
+}
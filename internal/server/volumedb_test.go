@@ -0,0 +1,161 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/user/alsamixer-web/internal/config"
+	"github.com/user/alsamixer-web/internal/sse"
+)
+
+// fakeDBMixer is a fakeMixer whose volumes can also be read and set in dB,
+// on a -60dB to 0dB scale.
+type fakeDBMixer struct {
+	*fakeMixer
+
+	dbMu sync.Mutex
+	db   []float64
+}
+
+func (f *fakeDBMixer) GetVolumeDB(card uint, control string) ([]float64, error) {
+	f.dbMu.Lock()
+	defer f.dbMu.Unlock()
+	return append([]float64(nil), f.db...), nil
+}
+
+func (f *fakeDBMixer) SetVolumeDB(card uint, control string, values []float64) error {
+	f.dbMu.Lock()
+	defer f.dbMu.Unlock()
+	f.db = append([]float64(nil), values...)
+	return nil
+}
+
+func (f *fakeDBMixer) VolumeDBRange(card uint, control string) (float64, float64, error) {
+	return -60, 0, nil
+}
+
+func postDBRelative(srv *Server, delta string) *httptest.ResponseRecorder {
+	form := url.Values{}
+	form.Set("card", "0")
+	form.Set("control", "Master Playback Volume")
+	form.Set("delta_db", delta)
+	req := httptest.NewRequest(http.MethodPost, "/control/volume/db-relative", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp := httptest.NewRecorder()
+	srv.mux.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestVolumeDBRelativeHandler(t *testing.T) {
+	cfg := &config.Config{
+		Port:     0,
+		BindAddr: "127.0.0.1",
+	}
+	hub := sse.NewHub()
+	go hub.Run()
+	srv := newTestServer(t, cfg, hub)
+
+	fm := &fakeDBMixer{fakeMixer: &fakeMixer{readBack: []int{80, 80}}, db: []float64{-20, -20}}
+	srv.useMixer(fm)
+
+	ts := httptest.NewServer(srv.mux)
+	t.Cleanup(ts.Close)
+	events := subscribeEvents(t, ts.URL, hub)
+
+	resp := postDBRelative(srv, "3")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+	var body struct {
+		DB     []float64 `json:"db"`
+		Volume int       `json:"volume"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if len(body.DB) != 2 || body.DB[0] != -17 || body.DB[1] != -17 {
+		t.Fatalf("expected +3dB to move -20dB to -17dB, got %v", body.DB)
+	}
+	if body.Volume != 80 {
+		t.Errorf("expected the resulting percentage 80, got %d", body.Volume)
+	}
+
+	data := waitForEvent(t, events, "mixer-update", time.Second)
+	var payload struct {
+		State map[string]map[string]struct {
+			Volume   []int
+			VolumeDB []float64
+		} `json:"state"`
+	}
+	if err := json.Unmarshal([]byte(data), &payload); err != nil {
+		t.Fatalf("decoding event data %q: %v", data, err)
+	}
+	got := payload.State["0"]["Master Playback Volume"]
+	if len(got.VolumeDB) != 2 || got.VolumeDB[0] != -17 || len(got.Volume) != 1 || got.Volume[0] != 80 {
+		t.Errorf("expected the broadcast to carry -17dB and 80%%, got %+v", got)
+	}
+
+	// The result is clamped to the control's dB range.
+	fm.SetVolumeDB(0, "Master Playback Volume", []float64{-1, -1})
+	if resp := postDBRelative(srv, "3"); resp.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.Code)
+	}
+	if db, _ := fm.GetVolumeDB(0, "Master Playback Volume"); db[0] != 0 {
+		t.Errorf("expected the level to be clamped at 0dB, got %v", db)
+	}
+
+	if resp := postDBRelative(srv, "loud"); resp.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d for an invalid delta, got %d", http.StatusBadRequest, resp.Code)
+	}
+	for _, delta := range []string{"NaN", "Inf", "-Inf"} {
+		if resp := postDBRelative(srv, delta); resp.Code != http.StatusBadRequest {
+			t.Errorf("expected status %d for delta %s, got %d", http.StatusBadRequest, delta, resp.Code)
+		}
+	}
+	if db, _ := fm.GetVolumeDB(0, "Master Playback Volume"); db[0] != 0 {
+		t.Errorf("expected a rejected delta to leave the level at 0dB, got %v", db)
+	}
+
+	// Controls without a dB scale say so.
+	srv.useMixer(fm.fakeMixer)
+	if resp := postDBRelative(srv, "3"); resp.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d without a dB scale, got %d", http.StatusBadRequest, resp.Code)
+	}
+}
+
+func TestVolumeDBRelativeHandlerDryRun(t *testing.T) {
+	cfg := &config.Config{
+		Port:     0,
+		BindAddr: "127.0.0.1",
+		DryRun:   true,
+	}
+	hub := sse.NewHub()
+	go hub.Run()
+	srv := newTestServer(t, cfg, hub)
+
+	fm := &fakeDBMixer{fakeMixer: &fakeMixer{readBack: []int{80, 80}}, db: []float64{-20, -20}}
+	srv.useMixer(fm)
+
+	resp := postDBRelative(srv, "3")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+	var body struct {
+		DB []float64 `json:"db"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if len(body.DB) != 2 || body.DB[0] != -17 {
+		t.Errorf("expected the intended -17dB in the response, got %v", body.DB)
+	}
+	if db, _ := fm.GetVolumeDB(0, "Master Playback Volume"); db[0] != -20 {
+		t.Errorf("expected the hardware to stay at -20dB in dry-run mode, got %v", db)
+	}
+}
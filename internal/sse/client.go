@@ -13,6 +13,8 @@ const heartbeatInterval = 25 * time.Second
 
 // Client represents an SSE client connection.
 type Client struct {
+	id      string      // chosen by the client with ?clientId=; may be empty
+	filter  EventFilter // nil sends every event
 	writer  http.ResponseWriter
 	ctx     context.Context
 	cancel  context.CancelFunc
@@ -34,13 +36,23 @@ func NewClient(w http.ResponseWriter, ctx context.Context) *Client {
 	}
 }
 
+// ID returns the identifier the client connected with, or "" if it gave
+// none.
+func (c *Client) ID() string {
+	return c.id
+}
+
+// filterEvent applies the client's filter to event.
+func (c *Client) filterEvent(event Event) (Event, bool) {
+	if c.filter == nil {
+		return event, true
+	}
+	return c.filter(event)
+}
+
 // WriteEvent sends an SSE formatted event to the client.
 func (c *Client) WriteEvent(event Event) error {
-	c.mu.Lock()
-	closed := c.closed
-	c.mu.Unlock()
-
-	if closed {
+	if c.IsClosed() {
 		return fmt.Errorf("client disconnected")
 	}
 
@@ -52,6 +64,14 @@ func (c *Client) WriteEvent(event Event) error {
 	}
 }
 
+// IsClosed reports whether the client has been closed, so callers can skip
+// it without attempting a write.
+func (c *Client) IsClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
+
 // Close signals the client to stop receiving events.
 func (c *Client) Close() {
 	c.mu.Lock()
@@ -1,12 +1,24 @@
 package sse
 
 import (
+	"encoding/json"
 	"log"
 	"net/http"
+	"regexp"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
+// clientIDPattern limits the ?clientId= a client may identify itself with.
+var clientIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// EventFilter decides what a client receives of an event: the event to
+// send in its place, such as a narrowed copy, and whether to send anything.
+// It must not modify the event it is given, which other clients share.
+type EventFilter func(Event) (Event, bool)
+
 // Hub manages SSE client connections and broadcasts events.
 type Hub struct {
 	clients    map[*Client]bool
@@ -15,6 +27,10 @@ type Hub struct {
 	broadcast  chan Event
 	stop       chan struct{}
 	mu         sync.Mutex
+
+	lastBroadcast  time.Time
+	broadcastBytes int64 // serialized size of every event broadcast so far
+	logSizes       bool
 }
 
 // NewHub creates a new SSE hub.
@@ -66,14 +82,31 @@ func (h *Hub) Run() {
 			log.Printf("Hub: client unregistered, total clients: %d", clientCount)
 
 		case event := <-h.broadcast:
+			size := int64(len(event.String()))
 			h.mu.Lock()
 			clientCount := len(h.clients)
+			h.lastBroadcast = time.Now()
+			h.broadcastBytes += size
+			total := h.broadcastBytes
+			logSizes := h.logSizes
 			h.mu.Unlock()
 			// Log the broadcast before sending to clients
 			log.Printf("[SSE] broadcasting to %d clients: type=%s", clientCount, event.Type)
+			if logSizes {
+				log.Printf("[SSE] broadcast size: type=%s bytes=%d total=%d", event.Type, size, total)
+			}
 			h.mu.Lock()
 			for client := range h.clients {
-				if err := client.WriteEvent(event); err != nil {
+				if client.IsClosed() {
+					// Already gone; drop it without a write attempt
+					delete(h.clients, client)
+					continue
+				}
+				filtered, ok := client.filterEvent(event)
+				if !ok {
+					continue
+				}
+				if err := client.WriteEvent(filtered); err != nil {
 					// Client disconnected or channel full, remove it
 					delete(h.clients, client)
 					client.Close()
@@ -106,8 +139,82 @@ func (h *Hub) ClientCount() int {
 	return len(h.clients)
 }
 
+// SendTo sends event only to the clients that connected with id, and
+// returns how many it reached.
+func (h *Hub) SendTo(id string, event Event) int {
+	if id == "" {
+		return 0
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sent := 0
+	for client := range h.clients {
+		if client.ID() != id || client.IsClosed() {
+			continue
+		}
+		filtered, ok := client.filterEvent(event)
+		if !ok {
+			continue
+		}
+		if err := client.WriteEvent(filtered); err != nil {
+			log.Printf("Hub: failed to send %s to client %s: %v", event.Type, id, err)
+			continue
+		}
+		sent++
+	}
+	return sent
+}
+
+// LastBroadcast returns when the most recent event was broadcast, or the
+// zero time if none has been.
+func (h *Hub) LastBroadcast() time.Time {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.lastBroadcast
+}
+
+// BroadcastBytes returns the total serialized size, in bytes, of every event
+// broadcast so far. Each event is counted once, however many clients it was
+// sent to.
+func (h *Hub) BroadcastBytes() int64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.broadcastBytes
+}
+
+// SetLogPayloadSizes makes the hub log the serialized size of each broadcast
+// event, to help diagnose bandwidth use.
+func (h *Hub) SetLogPayloadSizes(enabled bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.logSizes = enabled
+}
+
+// ServeHealth reports the number of connected SSE clients without opening a
+// stream or registering a client, so monitoring tools can check liveness
+// cheaply. The count is sent both as an X-SSE-Clients header and as JSON.
+func (h *Hub) ServeHealth(w http.ResponseWriter, r *http.Request) {
+	count := h.ClientCount()
+
+	w.Header().Set("X-SSE-Clients", strconv.Itoa(count))
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "ok",
+		"clients": count,
+	})
+}
+
 // ServeHTTP handles HTTP requests and registers new clients.
 func (h *Hub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.ServeFiltered(w, r, nil)
+}
+
+// ServeFiltered is ServeHTTP for a client that only receives what filter
+// lets through. A nil filter lets everything through.
+func (h *Hub) ServeFiltered(w http.ResponseWriter, r *http.Request, filter EventFilter) {
 	log.Printf("SSE request received: %s %s Accept=%s", r.Method, r.URL.Path, r.Header.Get("Accept"))
 
 	// Check request method (must be GET)
@@ -125,9 +232,17 @@ func (h *Hub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	clientID := r.URL.Query().Get("clientId")
+	if clientID != "" && !clientIDPattern.MatchString(clientID) {
+		http.Error(w, "invalid clientId", http.StatusBadRequest)
+		return
+	}
+
 	log.Printf("SSE: creating client")
 	// Create and register new client
 	client := NewClient(w, r.Context())
+	client.id = clientID
+	client.filter = filter
 	h.Register(client)
 	defer h.Unregister(client)
 
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"log"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -354,6 +355,24 @@ func TestClientWriteEvent(t *testing.T) {
 	}
 }
 
+func TestClientIsClosed(t *testing.T) {
+	client := NewClient(newMockResponseWriter(), context.Background())
+	if client.IsClosed() {
+		t.Fatal("expected new client to be open")
+	}
+
+	client.Close()
+	if !client.IsClosed() {
+		t.Error("expected client to report closed after Close")
+	}
+
+	// Close is idempotent
+	client.Close()
+	if !client.IsClosed() {
+		t.Error("expected client to stay closed after a second Close")
+	}
+}
+
 // TestHubServeHTTP tests the HTTP handler
 func TestHubServeHTTP(t *testing.T) {
 	hub := NewHub()
@@ -449,3 +468,69 @@ func TestHubServeHTTPWrongAcceptType(t *testing.T) {
 		t.Error("Test timed out")
 	}
 }
+
+// TestHubServeHealth tests that the health endpoint reports the client count
+// without registering a client itself
+func TestHubServeHealth(t *testing.T) {
+	hub := NewHub()
+	go hub.Run()
+
+	client := NewClient(newMockResponseWriter(), context.Background())
+	hub.Register(client)
+	time.Sleep(10 * time.Millisecond)
+
+	req := httptest.NewRequest("GET", "/events/health", nil)
+	rr := httptest.NewRecorder()
+	hub.ServeHealth(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	if got := rr.Header().Get("X-SSE-Clients"); got != "1" {
+		t.Errorf("Expected X-SSE-Clients: 1, got %q", got)
+	}
+	if !strings.Contains(rr.Body.String(), `"clients":1`) {
+		t.Errorf("Expected body to report 1 client, got %s", rr.Body.String())
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	if count := hub.ClientCount(); count != 1 {
+		t.Errorf("Expected health check not to register a client, got %d clients", count)
+	}
+}
+
+func TestHubBroadcastBytes(t *testing.T) {
+	logs := newMockResponseWriter()
+	orig := log.Writer()
+	log.SetOutput(logs)
+	defer log.SetOutput(orig)
+
+	hub := NewHub()
+	hub.SetLogPayloadSizes(true)
+	go hub.Run()
+	defer hub.Stop()
+
+	event := Event{Type: "mixer-update", Data: map[string]interface{}{"volume": 75, "muted": false}}
+	size := int64(len(event.String()))
+
+	hub.Broadcast(event)
+	// The hub handles one broadcast at a time, so once the second is
+	// accepted the first has been counted.
+	hub.Broadcast(Event{Type: "ping", Data: ""})
+
+	if got := hub.BroadcastBytes(); got < size {
+		t.Fatalf("expected at least %d bytes counted, got %d", size, got)
+	}
+	if want := fmt.Sprintf("type=mixer-update bytes=%d total=%d", size, size); !strings.Contains(logs.String(), want) {
+		t.Errorf("expected log to contain %q, got:\n%s", want, logs.String())
+	}
+
+	second := int64(len(Event{Type: "ping", Data: ""}.String()))
+	deadline := time.Now().Add(time.Second)
+	for hub.BroadcastBytes() != size+second {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected total %d bytes, got %d", size+second, hub.BroadcastBytes())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -55,6 +55,21 @@
     return incoming === active
   }
 
+  // Newest timestamp applied per control; handler echoes and monitor updates
+  // can arrive out of order, so anything older than what we've shown is dropped
+  var lastUpdateAt = {}
+
+  function isStale(cardId, controlName, timestamp) {
+    if (typeof timestamp !== 'number') return false
+    var id = getControlId(cardId, controlName)
+    if (lastUpdateAt[id] && lastUpdateAt[id] > timestamp) {
+      debug.log('[SSE] dropping stale update:', id, timestamp, '<', lastUpdateAt[id])
+      return true
+    }
+    lastUpdateAt[id] = timestamp
+    return false
+  }
+
   function toArray(list) {
     return Array.prototype.slice.call(list || [])
   }
@@ -82,6 +97,12 @@
     var control = findControl(cardId, controlName)
     if (!control) return
 
+    var stepper = control.querySelector('.mixer-control__stepper')
+    if (stepper) {
+      updateStepper(stepper, volume)
+      return
+    }
+
     var slider = control.querySelector('.mixer-control__volume[role="slider"]')
     if (!slider) return
 
@@ -96,6 +117,20 @@
     }
   }
 
+  // Steppers show the raw value; updates carry a percentage of the range
+  function updateStepper(stepper, volume) {
+    var min = parseInt(stepper.dataset.rawMin, 10) || 0
+    var max = parseInt(stepper.dataset.rawMax, 10) || 0
+    var percent = Math.max(0, Math.min(100, parseInt(volume, 10) || 0))
+    var raw = min + Math.round((percent * (max - min)) / 100)
+    stepper.setAttribute('aria-valuenow', String(raw))
+
+    var valueEl = stepper.querySelector('.mixer-control__value')
+    if (valueEl) {
+      valueEl.textContent = String(raw)
+    }
+  }
+
   function updateMute(cardId, controlName, muted) {
     // Skip ALL updates during active drag
     if (activeDragControl && isControlInPayload(controlName)) {
@@ -207,6 +242,7 @@
         Object.keys(controls).forEach(function (controlName) {
           var state = controls[controlName]
           if (!state) return
+          if (isStale(cardId, controlName, payload.timestamp)) return
           if (Array.isArray(state.Volume) && state.Volume.length) {
             updateVolume(cardId, controlName, state.Volume[0])
           }
@@ -224,6 +260,7 @@
         Object.keys(cardState).forEach(function (controlName) {
           var state = cardState[controlName]
           if (!state) return
+          if (isStale(cardId, controlName, payload.timestamp)) return
           if (Array.isArray(state.Volume) && state.Volume.length) {
             updateVolume(cardId, controlName, state.Volume[0])
           }
@@ -235,13 +272,32 @@
     }
   }
 
+  // Identifies this page's event stream, so it can ask for a fresh snapshot
+  // of the mixer state after reconnecting
+  var clientId = window.crypto && window.crypto.randomUUID
+    ? window.crypto.randomUUID()
+    : Date.now().toString(36) + '-' + Math.random().toString(36).slice(2)
+
+  function requestSnapshot() {
+    fetch('/events/refresh?clientId=' + encodeURIComponent(clientId), { method: 'POST' })
+      .catch(function (e) {
+        debug.log('[SSE] refresh failed:', e)
+      })
+  }
+
   function setupSSE() {
-    var source = new EventSource('/events')
+    var source = new EventSource('/events?clientId=' + encodeURIComponent(clientId))
+    var reconnecting = false
 
     // Connection status handling
     var statusEl = document.getElementById('connection-status')
     source.onopen = function() {
       debug.log('[SSE] ✅ connected')
+      if (reconnecting) {
+        // Updates sent while disconnected were missed
+        requestSnapshot()
+        reconnecting = false
+      }
       if (statusEl) {
         statusEl.classList.remove('is-disconnected')
         var valueEl = statusEl.querySelector('[data-connection-state]')
@@ -250,6 +306,7 @@
     }
     source.onerror = function() {
       debug.log('[SSE] ❌ disconnected')
+      reconnecting = true
       if (statusEl) {
         statusEl.classList.add('is-disconnected')
         var valueEl = statusEl.querySelector('[data-connection-state]')
@@ -290,6 +347,13 @@
       // Could reload page or update UI for config changes
     })
 
+    // Handle card-list-change events (hotplug or manual rescan) - the rendered
+    // card selector and controls are stale, so reload the page
+    source.addEventListener('card-list-change', function (event) {
+      debug.log('[SSE card-list-change]', event.data)
+      window.location.reload()
+    })
+
     // Fallback: handle any unnamed messages
     source.onmessage = function (event) {
       debug.log('[SSE message]', event.data)
@@ -178,9 +178,13 @@
     }
   }
 
+  function isReadOnly(slider) {
+    return slider.getAttribute('aria-readonly') === 'true'
+  }
+
   function handlePointerDown(event) {
     var slider = event.target.closest('.mixer-control__volume[role="slider"]')
-    if (!slider) return
+    if (!slider || isReadOnly(slider)) return
 
     activeSlider = slider
     lastSentVolume = null
@@ -246,19 +250,50 @@
     activeSlider = null
   }
 
+  // Fallback when the page doesn't provide a server-configured map
+  var defaultShortcuts = {
+    'volume-up': ['ArrowUp', 'ArrowRight'],
+    'volume-down': ['ArrowDown', 'ArrowLeft'],
+    mute: ['m'],
+    capture: ['c']
+  }
+
+  function shortcutAction(key) {
+    var shortcuts = (window.app && window.app.shortcuts) || defaultShortcuts
+    for (var action in shortcuts) {
+      if (!Object.prototype.hasOwnProperty.call(shortcuts, action)) continue
+      if ((shortcuts[action] || []).indexOf(key) !== -1) return action
+    }
+    return null
+  }
+
+  // Toggle mute or capture on the control that has keyboard focus
+  function toggleFocusedControl(event, kind) {
+    var control = event.target.closest('.mixer-control')
+    if (!control) return
+
+    var toggle = control.querySelector('.mixer-control__toggle--' + kind)
+    if (!toggle || toggle.disabled) return
+
+    event.preventDefault()
+    debug.log('[shortcut] ' + kind + ':', control.dataset.controlName)
+    toggle.click()
+  }
+
   function handleKeyDown(event) {
-    var slider = event.target.closest('.mixer-control__volume[role="slider"]')
-    if (!slider) return
-
-    if (
-      event.key !== 'ArrowLeft' &&
-      event.key !== 'ArrowRight' &&
-      event.key !== 'ArrowUp' &&
-      event.key !== 'ArrowDown'
-    ) {
+    if (event.ctrlKey || event.metaKey || event.altKey) return
+
+    var action = shortcutAction(event.key)
+    if (!action) return
+
+    if (action === 'mute' || action === 'capture') {
+      toggleFocusedControl(event, action)
       return
     }
 
+    var slider = event.target.closest('.mixer-control__volume[role="slider"]')
+    if (!slider || isReadOnly(slider)) return
+
     event.preventDefault()
 
     // Use step size for keyboard navigation too
@@ -268,10 +303,7 @@
     var min = parseIntAttr(slider, 'aria-valuemin', 0)
     var max = parseIntAttr(slider, 'aria-valuemax', 100)
     var current = parseIntAttr(slider, 'aria-valuenow', 0)
-    var delta = step
-    if (event.key === 'ArrowLeft' || event.key === 'ArrowDown') {
-      delta = -step
-    }
+    var delta = action === 'volume-down' ? -step : step
     var next = clamp(current + delta, min, max)
     
     if (next === current) {
@@ -0,0 +1,95 @@
+// Service worker for the app shell. Static assets and the last rendered page
+// are cached so the mixer opens on flaky networks; live state still needs the
+// SSE connection, and control changes always go to the network.
+// Bumped to v2 so activation drops v1 caches, which could hold GET action
+// URLs carrying the action token.
+var CACHE_NAME = 'alsamixer-web-shell-v2'
+
+var SHELL_ASSETS = [
+  '/',
+  '/manifest.json',
+  '/favicon.ico',
+  '/static/icons/icon-192.png',
+  '/static/icons/icon-512.png',
+  '/static/css/base.css',
+  '/static/css/accessibility.css',
+  '/static/themes/linux-console.css',
+  '/static/js/htmx.min.js',
+  '/static/js/mixer-volume.js',
+  '/static/js/mixer-view.js',
+  '/static/js/mixer-sync.js'
+]
+
+self.addEventListener('install', function (event) {
+  event.waitUntil(
+    caches.open(CACHE_NAME).then(function (cache) {
+      return cache.addAll(SHELL_ASSETS)
+    }).then(function () {
+      return self.skipWaiting()
+    })
+  )
+})
+
+self.addEventListener('activate', function (event) {
+  event.waitUntil(
+    caches.keys().then(function (keys) {
+      return Promise.all(keys.filter(function (key) {
+        return key !== CACHE_NAME
+      }).map(function (key) {
+        return caches.delete(key)
+      }))
+    }).then(function () {
+      return self.clients.claim()
+    })
+  )
+})
+
+function isShellRequest(url) {
+  if (url.origin !== self.location.origin) return false
+  if (url.pathname === '/events' || url.pathname.indexOf('/events/') === 0) return false
+  if (url.pathname.indexOf('/api/') === 0 || url.pathname.indexOf('/debug/') === 0) return false
+  // GET actions carry the action token, which must not be written to disk
+  if (url.pathname.indexOf('/action/') === 0 || url.pathname.indexOf('/admin/') === 0) return false
+  if (url.pathname === '/status' || url.pathname === '/metrics') return false
+  return true
+}
+
+self.addEventListener('fetch', function (event) {
+  var request = event.request
+  if (request.method !== 'GET') return
+
+  var url = new URL(request.url)
+  if (!isShellRequest(url)) return
+
+  // Pages: network first so the rendered state is fresh, cached copy offline
+  if (request.mode === 'navigate') {
+    event.respondWith(
+      fetch(request).then(function (response) {
+        if (response.ok) {
+          var copy = response.clone()
+          caches.open(CACHE_NAME).then(function (cache) { cache.put(request, copy) })
+        }
+        return response
+      }).catch(function () {
+        return caches.match(request).then(function (cached) {
+          return cached || caches.match('/')
+        })
+      })
+    )
+    return
+  }
+
+  // Static assets: serve from cache, refresh in the background
+  event.respondWith(
+    caches.match(request).then(function (cached) {
+      var network = fetch(request).then(function (response) {
+        if (response.ok) {
+          var copy = response.clone()
+          caches.open(CACHE_NAME).then(function (cache) { cache.put(request, copy) })
+        }
+        return response
+      })
+      return cached || network
+    })
+  )
+})
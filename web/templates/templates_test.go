@@ -21,14 +21,21 @@ type ControlView struct {
 	CardID      uint
 
 	HasVolume       bool
+	Kind            string
+	RawNow          int
+	IsPrimary       bool
 	VolumeAriaLabel string
 	VolumeMin       int
 	VolumeMax       int
 	VolumeStep      int
 	VolumeNow       int
 	VolumeText      string
+	RawMin          int64
+	RawMax          int64
+	Channels        int
 
 	HasMute       bool
+	MuteState     string
 	MuteAriaLabel string
 	Muted         bool
 
@@ -36,6 +43,7 @@ type ControlView struct {
 	CaptureAriaLabel string
 	CaptureActive    bool
 	View             string
+	ReadOnly         bool
 }
 
 // CardView represents a sound card and its controls for rendering.
@@ -44,6 +52,14 @@ type CardView struct {
 	Name        string
 	Description string
 	Controls    []ControlView
+	Groups      []ControlGroup
+}
+
+// ControlGroup is a titled Playback or Capture section of a card.
+type ControlGroup struct {
+	Title    string
+	View     string
+	Controls []ControlView
 }
 
 // ControlsPage is the top-level data structure passed into the
@@ -72,34 +88,37 @@ func TestControlsTemplateExecutesWithMockData(t *testing.T) {
 		t.Fatalf("failed to parse controls template: %v", err)
 	}
 
+	master := ControlView{
+		ID:               "master",
+		Name:             "Master Playback Volume",
+		BaseName:         "Master",
+		Description:      "Master playback volume",
+		CardID:           0,
+		HasVolume:        true,
+		VolumeAriaLabel:  "Master volume",
+		VolumeMin:        0,
+		VolumeMax:        100,
+		VolumeStep:       1,
+		VolumeNow:        75,
+		VolumeText:       "75%",
+		HasMute:          true,
+		MuteAriaLabel:    "Mute Master",
+		Muted:            false,
+		HasCapture:       true,
+		CaptureAriaLabel: "Capture Master",
+		CaptureActive:    true,
+		View:             "playback",
+	}
+
 	page := ControlsPage{
 		Cards: []CardView{
 			{
 				ID:          0,
 				Name:        "Test Card",
 				Description: "Primary sound card for testing",
-				Controls: []ControlView{
-					{
-						ID:               "master",
-						Name:             "Master Playback Volume",
-						BaseName:         "Master",
-						Description:      "Master playback volume",
-						CardID:           0,
-						HasVolume:        true,
-						VolumeAriaLabel:  "Master volume",
-						VolumeMin:        0,
-						VolumeMax:        100,
-						VolumeStep:       1,
-						VolumeNow:        75,
-						VolumeText:       "75%",
-						HasMute:          true,
-						MuteAriaLabel:    "Mute Master",
-						Muted:            false,
-						HasCapture:       true,
-						CaptureAriaLabel: "Capture Master",
-						CaptureActive:    true,
-						View:             "playback",
-					},
+				Controls:    []ControlView{master},
+				Groups: []ControlGroup{
+					{Title: "Playback", View: "playback", Controls: []ControlView{master}},
 				},
 			},
 		},
@@ -135,3 +154,119 @@ func TestControlsTemplateExecutesWithMockData(t *testing.T) {
 		}
 	}
 }
+
+func TestControlTemplateReadOnly(t *testing.T) {
+	tmpl, err := template.ParseFiles(controlsTemplatePath)
+	if err != nil {
+		t.Fatalf("failed to parse controls template: %v", err)
+	}
+
+	ctrl := ControlView{
+		ID:         "master",
+		Name:       "Master Playback Volume",
+		BaseName:   "Master",
+		HasVolume:  true,
+		VolumeMax:  100,
+		VolumeNow:  40,
+		VolumeText: "40%",
+		HasMute:    true,
+		ReadOnly:   true,
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, "control", ctrl); err != nil {
+		t.Fatalf("failed to execute control template: %v", err)
+	}
+	out := buf.String()
+
+	for _, token := range []string{"aria-readonly=\"true\"", "disabled", "display only"} {
+		if !strings.Contains(out, token) {
+			t.Errorf("read-only control missing %q. Output: %s", token, out)
+		}
+	}
+	if strings.Contains(out, "hx-post") {
+		t.Errorf("read-only control should not post changes. Output: %s", out)
+	}
+}
+
+func TestControlTemplateRangeTooltip(t *testing.T) {
+	tmpl, err := template.ParseFiles(controlsTemplatePath)
+	if err != nil {
+		t.Fatalf("failed to parse controls template: %v", err)
+	}
+
+	ctrl := ControlView{
+		ID:         "pcm",
+		Name:       "PCM Playback Volume",
+		BaseName:   "PCM",
+		HasVolume:  true,
+		VolumeMax:  100,
+		VolumeStep: 4,
+		VolumeNow:  50,
+		VolumeText: "50%",
+		RawMin:     -10239,
+		RawMax:     400,
+		Channels:   2,
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, "control", ctrl); err != nil {
+		t.Fatalf("failed to execute control template: %v", err)
+	}
+	out := buf.String()
+
+	want := `title="Raw range -10239–400, 4% per step, 2 channels"`
+	if !strings.Contains(out, want) {
+		t.Errorf("expected slider tooltip %s. Output: %s", want, out)
+	}
+}
+
+func TestControlsTemplateGroupsByView(t *testing.T) {
+	tmpl, err := template.ParseFiles(controlsTemplatePath)
+	if err != nil {
+		t.Fatalf("failed to parse controls template: %v", err)
+	}
+
+	master := ControlView{ID: "master", Name: "Master Playback Volume", HasVolume: true, View: "playback"}
+	mic := ControlView{ID: "mic", Name: "Mic Capture Volume", HasVolume: true, View: "capture"}
+
+	page := ControlsPage{
+		Cards: []CardView{
+			{
+				ID:       0,
+				Name:     "Test Card",
+				Controls: []ControlView{master, mic},
+				Groups: []ControlGroup{
+					{Title: "Playback", View: "playback", Controls: []ControlView{master}},
+					{Title: "Capture", View: "capture", Controls: []ControlView{mic}},
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, "controls", page); err != nil {
+		t.Fatalf("failed to execute controls template: %v", err)
+	}
+	out := buf.String()
+
+	playback := strings.Index(out, `data-group-view="playback"`)
+	capture := strings.Index(out, `data-group-view="capture"`)
+	if playback < 0 || capture < 0 {
+		t.Fatalf("expected both Playback and Capture sections. Output: %s", out)
+	}
+	for _, title := range []string{">Playback</h3>", ">Capture</h3>"} {
+		if !strings.Contains(out, title) {
+			t.Errorf("expected section header %q. Output: %s", title, out)
+		}
+	}
+
+	masterAt := strings.Index(out, `data-control-name="Master Playback Volume"`)
+	micAt := strings.Index(out, `data-control-name="Mic Capture Volume"`)
+	if !(playback < masterAt && masterAt < capture) {
+		t.Errorf("expected Master under the Playback section")
+	}
+	if micAt < capture {
+		t.Errorf("expected Mic under the Capture section")
+	}
+}